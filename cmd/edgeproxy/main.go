@@ -0,0 +1,297 @@
+// Command edgeproxy is the reverse proxy the discovery, tls/acme, and
+// probe packages exist to serve: it watches a discovery.Registry for
+// backend changes, round-robins traffic across the current healthy set,
+// and exposes a health endpoint, mirroring contacts-api's startup/shutdown
+// conventions.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/andrebassi/edgeproxy/discovery"
+	"github.com/andrebassi/edgeproxy/probe"
+	"github.com/andrebassi/edgeproxy/tls/acme"
+)
+
+var backendPool *discovery.Pool
+
+var rrNext uint64
+
+// geoBalancer is set in main from newProber when CLIENT_REGION is configured;
+// selectBackend prefers it over plain round-robin whenever it's non-nil.
+var geoBalancer *probe.GeoLatencyBalancer
+
+// clientRegionHeader names the header a fronting load balancer (or the
+// client directly) sets with the requester's region, the same field
+// discovery.Backend.Region and the mock backend's /api/latency use.
+const clientRegionHeader = "X-Client-Region"
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// newRegistry builds the discovery.Registry selected by DISCOVERY_BACKEND
+// (consul, etcd, k8s); unset or anything else is an error, which main
+// treats as fatal.
+func newRegistry() (discovery.Registry, error) {
+	switch getEnv("DISCOVERY_BACKEND", "") {
+	case "consul":
+		return discovery.NewConsulRegistry(getEnv("CONSUL_ADDR", "http://127.0.0.1:8500"), getEnv("CONSUL_SERVICE", "edgeproxy-backend")), nil
+	case "etcd":
+		return discovery.NewEtcdRegistry(getEnv("ETCD_ADDR", "http://127.0.0.1:2379"), getEnv("ETCD_PREFIX", "/edgeproxy/backends/")), nil
+	case "k8s":
+		return discovery.NewK8sRegistry(getEnv("K8S_NAMESPACE", "default"), getEnv("K8S_SERVICE", "edgeproxy-backend"))
+	default:
+		return nil, fmt.Errorf("edgeproxy: DISCOVERY_BACKEND must be one of consul, etcd, k8s")
+	}
+}
+
+// selectBackend picks the backend a request should be proxied to. When
+// geoBalancer is configured it picks the lowest-latency healthy backend
+// for the request's X-Client-Region; otherwise (or if that region has no
+// healthy candidate) it falls back to plain round-robin over the pool's
+// current healthy snapshot.
+func selectBackend(r *http.Request) (discovery.Backend, error) {
+	if geoBalancer != nil {
+		region := r.Header.Get(clientRegionHeader)
+		if backend, err := geoBalancer.Pick(region); err == nil {
+			return backend, nil
+		}
+	}
+
+	backends := backendPool.Backends()
+	if len(backends) == 0 {
+		return discovery.Backend{}, fmt.Errorf("edgeproxy: no healthy backends available")
+	}
+	i := atomic.AddUint64(&rrNext, 1)
+	return backends[i%uint64(len(backends))], nil
+}
+
+// newProber builds the Prober/GeoLatencyBalancer pair for CLIENT_REGION,
+// or returns (nil, nil, nil) when it's unset so edgeproxy can still run
+// with plain round-robin routing.
+func newProber() (*probe.Prober, *probe.GeoLatencyBalancer, error) {
+	region := getEnv("CLIENT_REGION", "")
+	if region == "" {
+		return nil, nil, nil
+	}
+
+	cfg := probe.Config{
+		ClientRegion: region,
+		Interval:     getDurationEnv("PROBE_INTERVAL", 10*time.Second),
+		Jitter:       getDurationEnv("PROBE_JITTER", 2*time.Second),
+		Timeout:      getDurationEnv("PROBE_TIMEOUT", 3*time.Second),
+	}
+	prober := probe.NewProber(cfg, backendPool.Backends)
+	balancer := probe.NewGeoLatencyBalancer(prober, backendPool.Backends)
+	return prober, balancer, nil
+}
+
+func proxyHandler(w http.ResponseWriter, r *http.Request) {
+	backend, err := selectBackend(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	target, err := url.Parse("http://" + backend.Address)
+	if err != nil {
+		http.Error(w, "edgeproxy: invalid backend address", http.StatusInternalServerError)
+		return
+	}
+	httputil.NewSingleHostReverseProxy(target).ServeHTTP(w, r)
+}
+
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"status":"ok","backends":%d}`, len(backendPool.Backends()))
+}
+
+// newACMEManager builds the certificate manager for the hostnames this
+// proxy terminates TLS for, or returns (nil, nil) when ACME_HOSTS isn't
+// set so edgeproxy can still run plain HTTP in dev/test. ACME_CHALLENGE
+// selects http-01 (default) or dns-01; dns-01 additionally requires
+// ACME_DNS_PROVIDER to pick which DNSProvider to construct.
+func newACMEManager() (*acme.Manager, error) {
+	hosts := strings.Split(getEnv("ACME_HOSTS", ""), ",")
+	for i := len(hosts) - 1; i >= 0; i-- {
+		hosts[i] = strings.TrimSpace(hosts[i])
+		if hosts[i] == "" {
+			hosts = append(hosts[:i], hosts[i+1:]...)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	cfg := acme.Config{
+		DirectoryURL: getEnv("ACME_DIRECTORY_URL", acme.LetsEncryptDirectory),
+		Hosts:        hosts,
+		Challenge:    acme.ChallengeType(getEnv("ACME_CHALLENGE", string(acme.ChallengeHTTP01))),
+		Storage:      acme.NewFileStorage(getEnv("ACME_STORAGE_DIR", "./acme-storage")),
+		Email:        getEnv("ACME_EMAIL", ""),
+	}
+
+	if cfg.Challenge == acme.ChallengeDNS01 {
+		provider, err := newDNSProvider()
+		if err != nil {
+			return nil, err
+		}
+		cfg.DNSProvider = provider
+	}
+
+	return acme.NewManager(cfg)
+}
+
+// newDNSProvider builds the DNSProvider selected by ACME_DNS_PROVIDER, for
+// dns-01 challenges - the same env-driven selection newRegistry uses for
+// DISCOVERY_BACKEND.
+func newDNSProvider() (acme.DNSProvider, error) {
+	switch getEnv("ACME_DNS_PROVIDER", "") {
+	case "cloudflare":
+		return acme.NewCloudflareProvider(getEnv("CLOUDFLARE_API_TOKEN", ""), getEnv("CLOUDFLARE_ZONE_ID", "")), nil
+	case "route53":
+		return acme.NewRoute53Provider(getEnv("AWS_ACCESS_KEY_ID", ""), getEnv("AWS_SECRET_ACCESS_KEY", ""), getEnv("AWS_REGION", "us-east-1"), getEnv("ROUTE53_ZONE_ID", "")), nil
+	case "gandi":
+		return acme.NewGandiProvider(getEnv("GANDI_API_KEY", ""), getEnv("GANDI_DOMAIN", "")), nil
+	default:
+		return nil, fmt.Errorf("edgeproxy: ACME_DNS_PROVIDER must be one of cloudflare, route53, gandi for dns-01")
+	}
+}
+
+func main() {
+	log.Println("Initializing edgeproxy...")
+
+	reg, err := newRegistry()
+	if err != nil {
+		log.Fatalf("Registry init failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backendPool, err = discovery.NewPool(ctx, reg)
+	if err != nil {
+		log.Fatalf("Discovery pool init failed: %v", err)
+	}
+	go func() {
+		if err := backendPool.Run(ctx); err != nil {
+			log.Printf("Discovery watch stopped: %v", err)
+		}
+	}()
+
+	acmeManager, err := newACMEManager()
+	if err != nil {
+		log.Fatalf("ACME manager init failed: %v", err)
+	}
+
+	prober, balancer, err := newProber()
+	if err != nil {
+		log.Fatalf("Prober init failed: %v", err)
+	}
+	if prober != nil {
+		geoBalancer = balancer
+		go prober.Run(ctx)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", healthHandler)
+	if acmeManager != nil {
+		mux.Handle("/admin/acme", acmeManager.AdminHandler())
+	}
+	if prober != nil {
+		mux.Handle("/debug/latency", prober.AdminHandler())
+		mux.Handle("/metrics", prober.MetricsHandler())
+	}
+	mux.HandleFunc("/", proxyHandler)
+
+	port := getEnv("PORT", "8443")
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: getDurationEnv("READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       getDurationEnv("READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:      getDurationEnv("WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       getDurationEnv("IDLE_TIMEOUT", 120*time.Second),
+	}
+
+	// When ACME is configured, edgeproxy terminates TLS itself using
+	// certs the Manager issues/renews, and runs a plaintext :80 listener
+	// serving only /.well-known/acme-challenge/ for HTTP-01 validation -
+	// the same split LetsEncrypt's own certbot expects.
+	var httpChallengeServer *http.Server
+	if acmeManager != nil {
+		go acmeManager.Run(ctx)
+
+		server.TLSConfig = &tls.Config{GetCertificate: acmeManager.GetCertificate}
+
+		httpChallengeServer = &http.Server{
+			Addr:    ":" + getEnv("ACME_HTTP_PORT", "80"),
+			Handler: acmeManager.HTTPHandler(),
+		}
+		go func() {
+			if err := httpChallengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("ACME HTTP-01 challenge server error: %v", err)
+			}
+		}()
+	}
+
+	shutdownComplete := make(chan struct{})
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+		<-sigCh
+		log.Println("Shutdown signal received, draining connections...")
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Graceful shutdown error: %v", err)
+		}
+		if httpChallengeServer != nil {
+			if err := httpChallengeServer.Shutdown(shutdownCtx); err != nil {
+				log.Printf("ACME HTTP-01 challenge server shutdown error: %v", err)
+			}
+		}
+		cancel() // stop the discovery watch and ACME renewal loops
+		close(shutdownComplete)
+	}()
+
+	log.Printf("Server starting on port %s", port)
+	var serveErr error
+	if acmeManager != nil {
+		serveErr = server.ListenAndServeTLS("", "") // certs come from TLSConfig.GetCertificate
+	} else {
+		serveErr = server.ListenAndServe()
+	}
+	if serveErr != nil && serveErr != http.ErrServerClosed {
+		log.Fatalf("Server error: %v", serveErr)
+	}
+	<-shutdownComplete
+}
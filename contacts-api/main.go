@@ -1,16 +1,30 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	_ "github.com/lib/pq"
+
+	"github.com/andrebassi/edgeproxy/internal/accesslog"
+	"github.com/andrebassi/edgeproxy/internal/auth"
+	"github.com/andrebassi/edgeproxy/internal/dbpool"
+	"github.com/andrebassi/edgeproxy/internal/metrics"
 )
 
 type Contact struct {
@@ -47,7 +61,11 @@ type StatsResponse struct {
 	DBHost          string    `json:"db_host"`
 }
 
-var db *sql.DB
+var pool *dbpool.Pool
+
+var metricsRegistry = metrics.NewRegistry()
+var rateLimiter = auth.NewLimiter()
+var authStore *auth.Store
 
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -56,6 +74,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// pickDB resolves the handle a request should use. defaultConsistency is
+// what the handler wants when the caller doesn't care; GET requests may
+// override it with ?consistency=strong to force the primary, which read-
+// your-writes benchmark tooling needs right after an insert.
+func pickDB(r *http.Request, defaultConsistency string) *dbpool.Handle {
+	consistency := defaultConsistency
+	if r.Method == http.MethodGet {
+		if c := r.URL.Query().Get("consistency"); c != "" {
+			consistency = c
+		}
+	}
+	return pool.Pick(consistency)
+}
+
 func initDB() error {
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
@@ -63,20 +95,47 @@ func initDB() error {
 	dbPassword := getEnv("DB_PASSWORD", "")
 	dbName := getEnv("DB_NAME", "contacts")
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
 		dbHost, dbPort, dbUser, dbPassword, dbName)
 
-	var err error
-	db, err = sql.Open("postgres", connStr)
+	primary, err := dbpool.Open(dsn, dbHost, metricsRegistry)
 	if err != nil {
 		return err
 	}
+	primary.SetMaxOpenConns(dbMaxOpenConns)
+	primary.SetMaxIdleConns(5)
+	primary.SetConnMaxLifetime(time.Minute * 5)
+
+	var replicas []*dbpool.Handle
+	for _, replicaDSN := range strings.Split(getEnv("DB_REPLICAS", ""), ",") {
+		replicaDSN = strings.TrimSpace(replicaDSN)
+		if replicaDSN == "" {
+			continue
+		}
+		replica, err := dbpool.Open(replicaDSN, replicaHost(replicaDSN), metricsRegistry)
+		if err != nil {
+			return err
+		}
+		replica.SetMaxOpenConns(dbMaxOpenConns)
+		replica.SetMaxIdleConns(5)
+		replica.SetConnMaxLifetime(time.Minute * 5)
+		replicas = append(replicas, replica)
+	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Minute * 5)
+	pool = dbpool.New(primary, replicas)
+	authStore = auth.NewStore(pool.Primary())
+	return nil
+}
 
-	return db.Ping()
+// replicaHost pulls the host= value out of a libpq DSN for reporting in
+// API responses; DB_REPLICAS entries are full DSNs, not bare hostnames.
+func replicaHost(dsn string) string {
+	for _, field := range strings.Fields(dsn) {
+		if host := strings.TrimPrefix(field, "host="); host != field {
+			return host
+		}
+	}
+	return dsn
 }
 
 func initSchema() error {
@@ -94,8 +153,8 @@ func initSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_contacts_name ON contacts(name);
 	CREATE INDEX IF NOT EXISTS idx_contacts_email ON contacts(email);
 	CREATE INDEX IF NOT EXISTS idx_contacts_company ON contacts(company);
-	`
-	_, err := db.Exec(schema)
+	` + auth.Schema
+	_, err := pool.Primary().Exec(schema)
 	return err
 }
 
@@ -111,16 +170,16 @@ func errorResponse(w http.ResponseWriter, message string, statusCode int) {
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	region := getEnv("FLY_REGION", "local")
-	dbHost := getEnv("DB_HOST", "localhost")
+	primary := pool.Primary()
 
 	resp := HealthResponse{
 		Status:   "healthy",
 		Database: "connected",
 		Region:   region,
-		DBHost:   dbHost,
+		DBHost:   primary.Host,
 	}
 
-	if err := db.Ping(); err != nil {
+	if err := primary.Ping(); err != nil {
 		resp.Status = "unhealthy"
 		resp.Database = err.Error()
 	}
@@ -146,7 +205,8 @@ func listContactsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	rows, err := db.Query(`
+	h := pickDB(r, dbpool.Eventual)
+	rows, err := h.Query(`
 		SELECT id, name, email, phone, company, notes, created_at, updated_at
 		FROM contacts ORDER BY name LIMIT $1 OFFSET $2
 	`, limit, offset)
@@ -167,7 +227,7 @@ func listContactsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var total int
-	db.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&total)
+	h.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&total)
 
 	jsonResponse(w, map[string]interface{}{
 		"contacts":  contacts,
@@ -175,6 +235,7 @@ func listContactsHandler(w http.ResponseWriter, r *http.Request) {
 		"limit":     limit,
 		"offset":    offset,
 		"served_by": getEnv("FLY_REGION", "local"),
+		"db_host":   h.Host,
 	}, http.StatusOK)
 }
 
@@ -187,7 +248,7 @@ func getContactHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var c Contact
-	err = db.QueryRow(`
+	err = pickDB(r, dbpool.Eventual).QueryRow(`
 		SELECT id, name, email, phone, company, notes, created_at, updated_at
 		FROM contacts WHERE id = $1
 	`, id).Scan(&c.ID, &c.Name, &c.Email, &c.Phone, &c.Company, &c.Notes, &c.CreatedAt, &c.UpdatedAt)
@@ -216,7 +277,7 @@ func createContactHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var c Contact
-	err := db.QueryRow(`
+	err := pool.Primary().QueryRow(`
 		INSERT INTO contacts (name, email, phone, company, notes)
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, name, email, phone, company, notes, created_at, updated_at
@@ -245,7 +306,7 @@ func updateContactHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var c Contact
-	err = db.QueryRow(`
+	err = pool.Primary().QueryRow(`
 		UPDATE contacts
 		SET name = $1, email = $2, phone = $3, company = $4, notes = $5, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $6
@@ -272,7 +333,7 @@ func deleteContactHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM contacts WHERE id = $1", id)
+	result, err := pool.Primary().Exec("DELETE FROM contacts WHERE id = $1", id)
 	if err != nil {
 		errorResponse(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -295,7 +356,8 @@ func searchContactsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	searchTerm := "%" + query + "%"
-	rows, err := db.Query(`
+	h := pickDB(r, dbpool.Eventual)
+	rows, err := h.Query(`
 		SELECT id, name, email, phone, company, notes, created_at, updated_at
 		FROM contacts
 		WHERE name ILIKE $1 OR email ILIKE $1 OR company ILIKE $1
@@ -321,6 +383,7 @@ func searchContactsHandler(w http.ResponseWriter, r *http.Request) {
 		"contacts": contacts,
 		"query":    query,
 		"count":    len(contacts),
+		"db_host":  h.Host,
 	}, http.StatusOK)
 }
 
@@ -328,11 +391,12 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 	var total, companies int
 	var latest *time.Time
 
-	db.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&total)
-	db.QueryRow("SELECT COUNT(DISTINCT company) FROM contacts WHERE company IS NOT NULL").Scan(&companies)
+	h := pickDB(r, dbpool.Eventual)
+	h.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&total)
+	h.QueryRow("SELECT COUNT(DISTINCT company) FROM contacts WHERE company IS NOT NULL").Scan(&companies)
 
 	var latestTime time.Time
-	err := db.QueryRow("SELECT created_at FROM contacts ORDER BY created_at DESC LIMIT 1").Scan(&latestTime)
+	err := h.QueryRow("SELECT created_at FROM contacts ORDER BY created_at DESC LIMIT 1").Scan(&latestTime)
 	if err == nil {
 		latest = &latestTime
 	}
@@ -342,7 +406,7 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 		UniqueCompanies: companies,
 		LatestContact:   latest,
 		ServedBy:        getEnv("FLY_REGION", "local"),
-		DBHost:          getEnv("DB_HOST", "localhost"),
+		DBHost:          h.Host,
 	}, http.StatusOK)
 }
 
@@ -360,17 +424,17 @@ type BenchmarkResult struct {
 func benchmarkReadHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	region := getEnv("FLY_REGION", "local")
-	dbHost := getEnv("DB_HOST", "localhost")
+	h := pickDB(r, dbpool.Eventual)
 
 	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&count)
+	err := h.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&count)
 	latency := float64(time.Since(start).Microseconds()) / 1000.0
 
 	result := BenchmarkResult{
 		Operation: "READ",
 		LatencyMs: latency,
 		Region:    region,
-		DBHost:    dbHost,
+		DBHost:    h.Host,
 		Success:   err == nil,
 	}
 	if err != nil {
@@ -383,13 +447,13 @@ func benchmarkReadHandler(w http.ResponseWriter, r *http.Request) {
 func benchmarkInsertHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	region := getEnv("FLY_REGION", "local")
-	dbHost := getEnv("DB_HOST", "localhost")
+	primary := pool.Primary()
 
 	// Insert a benchmark record
 	name := fmt.Sprintf("Benchmark-%s-%d", region, time.Now().UnixNano())
 	email := fmt.Sprintf("bench-%d@test.local", time.Now().UnixNano())
 
-	result, err := db.Exec(`
+	result, err := primary.Exec(`
 		INSERT INTO contacts (name, email, notes)
 		VALUES ($1, $2, $3)
 	`, name, email, "Benchmark test record")
@@ -400,7 +464,7 @@ func benchmarkInsertHandler(w http.ResponseWriter, r *http.Request) {
 		Operation: "INSERT",
 		LatencyMs: latency,
 		Region:    region,
-		DBHost:    dbHost,
+		DBHost:    primary.Host,
 		Success:   err == nil,
 	}
 	if err != nil {
@@ -413,9 +477,93 @@ func benchmarkInsertHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, benchResult, http.StatusOK)
 }
 
+// benchStats summarizes a set of latency samples in milliseconds.
+type benchStats struct {
+	Avg    float64
+	Min    float64
+	Max    float64
+	P50    float64
+	P95    float64
+	P99    float64
+	Stddev float64
+}
+
+// computeBenchStats copies and sorts latencies so percentiles can be read
+// off by interpolating between adjacent samples, which gives sensible
+// numbers even with the small iteration counts benchmark callers tend to
+// use.
+func computeBenchStats(latencies []float64) benchStats {
+	if len(latencies) == 0 {
+		return benchStats{}
+	}
+
+	sorted := make([]float64, len(latencies))
+	copy(sorted, latencies)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, l := range sorted {
+		sum += l
+	}
+	avg := sum / float64(len(sorted))
+
+	var variance float64
+	for _, l := range sorted {
+		variance += (l - avg) * (l - avg)
+	}
+	variance /= float64(len(sorted))
+
+	return benchStats{
+		Avg:    avg,
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		P50:    percentile(sorted, 0.50),
+		P95:    percentile(sorted, 0.95),
+		P99:    percentile(sorted, 0.99),
+		Stddev: math.Sqrt(variance),
+	}
+}
+
+// percentile assumes sorted is already sorted ascending.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	idx := p * float64(len(sorted)-1)
+	lower := int(math.Floor(idx))
+	upper := int(math.Ceil(idx))
+	frac := idx - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
+
+// runConcurrent runs work for indices [0, total) across concurrency
+// goroutines, each pulling the next index off a shared counter, and
+// collects every result into a slice ordered by index.
+func runConcurrent(total, concurrency int, work func(i int) float64) ([]float64, error) {
+	results := make([]float64, total)
+	var next int64
+
+	g, _ := errgroup.WithContext(context.Background())
+	for n := 0; n < concurrency; n++ {
+		g.Go(func() error {
+			for {
+				i := int(atomic.AddInt64(&next, 1)) - 1
+				if i >= total {
+					return nil
+				}
+				results[i] = work(i)
+			}
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func benchmarkFullHandler(w http.ResponseWriter, r *http.Request) {
 	region := getEnv("FLY_REGION", "local")
-	dbHost := getEnv("DB_HOST", "localhost")
+	primary := pool.Primary()
 
 	iterations := 10
 	if iter := r.URL.Query().Get("iterations"); iter != "" {
@@ -424,79 +572,104 @@ func benchmarkFullHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	warmup := 0
+	if wp := r.URL.Query().Get("warmup"); wp != "" {
+		if n, err := strconv.Atoi(wp); err == nil && n >= 0 && n <= 100 {
+			warmup = n
+		}
+	}
+
+	concurrency := 1
+	if c := r.URL.Query().Get("concurrency"); c != "" {
+		if n, err := strconv.Atoi(c); err == nil && n >= 1 && n <= 32 {
+			concurrency = n
+		}
+	}
+
+	raw := r.URL.Query().Get("raw") == "true"
+
 	type FullBenchmark struct {
-		Region       string    `json:"region"`
-		DBHost       string    `json:"db_host"`
-		Iterations   int       `json:"iterations"`
-		ReadAvgMs    float64   `json:"read_avg_ms"`
-		ReadMinMs    float64   `json:"read_min_ms"`
-		ReadMaxMs    float64   `json:"read_max_ms"`
-		InsertAvgMs  float64   `json:"insert_avg_ms"`
-		InsertMinMs  float64   `json:"insert_min_ms"`
-		InsertMaxMs  float64   `json:"insert_max_ms"`
-		ReadLatencies  []float64 `json:"read_latencies"`
-		InsertLatencies []float64 `json:"insert_latencies"`
-		Timestamp    string    `json:"timestamp"`
-	}
-
-	readLatencies := make([]float64, iterations)
-	insertLatencies := make([]float64, iterations)
-
-	// Run READ benchmarks
-	for i := 0; i < iterations; i++ {
+		Region          string    `json:"region"`
+		DBHost          string    `json:"db_host"`
+		Iterations      int       `json:"iterations"`
+		Warmup          int       `json:"warmup"`
+		Concurrency     int       `json:"concurrency"`
+		ReadAvgMs       float64   `json:"read_avg_ms"`
+		ReadMinMs       float64   `json:"read_min_ms"`
+		ReadMaxMs       float64   `json:"read_max_ms"`
+		ReadP50Ms       float64   `json:"read_p50_ms"`
+		ReadP95Ms       float64   `json:"read_p95_ms"`
+		ReadP99Ms       float64   `json:"read_p99_ms"`
+		ReadStddevMs    float64   `json:"read_stddev_ms"`
+		InsertAvgMs     float64   `json:"insert_avg_ms"`
+		InsertMinMs     float64   `json:"insert_min_ms"`
+		InsertMaxMs     float64   `json:"insert_max_ms"`
+		InsertP50Ms     float64   `json:"insert_p50_ms"`
+		InsertP95Ms     float64   `json:"insert_p95_ms"`
+		InsertP99Ms     float64   `json:"insert_p99_ms"`
+		InsertStddevMs  float64   `json:"insert_stddev_ms"`
+		ReadLatencies   []float64 `json:"read_latencies,omitempty"`
+		InsertLatencies []float64 `json:"insert_latencies,omitempty"`
+		Timestamp       string    `json:"timestamp"`
+	}
+
+	total := warmup + iterations
+
+	readAll, err := runConcurrent(total, concurrency, func(i int) float64 {
 		start := time.Now()
 		var count int
-		db.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&count)
-		readLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
+		primary.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&count)
+		return float64(time.Since(start).Microseconds()) / 1000.0
+	})
+	if err != nil {
+		errorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	// Run INSERT benchmarks
-	for i := 0; i < iterations; i++ {
+	insertAll, err := runConcurrent(total, concurrency, func(i int) float64 {
 		start := time.Now()
 		name := fmt.Sprintf("Bench-%s-%d-%d", region, time.Now().UnixNano(), i)
 		email := fmt.Sprintf("bench-%d@test.local", time.Now().UnixNano())
-		db.Exec(`INSERT INTO contacts (name, email, notes) VALUES ($1, $2, $3)`,
+		primary.Exec(`INSERT INTO contacts (name, email, notes) VALUES ($1, $2, $3)`,
 			name, email, "Benchmark")
-		insertLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
-	}
-
-	// Calculate stats
-	calcStats := func(latencies []float64) (avg, min, max float64) {
-		if len(latencies) == 0 {
-			return 0, 0, 0
-		}
-		min = latencies[0]
-		max = latencies[0]
-		var sum float64
-		for _, l := range latencies {
-			sum += l
-			if l < min {
-				min = l
-			}
-			if l > max {
-				max = l
-			}
-		}
-		avg = sum / float64(len(latencies))
+		return float64(time.Since(start).Microseconds()) / 1000.0
+	})
+	if err != nil {
+		errorResponse(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	readAvg, readMin, readMax := calcStats(readLatencies)
-	insertAvg, insertMin, insertMax := calcStats(insertLatencies)
+	readLatencies := readAll[warmup:]
+	insertLatencies := insertAll[warmup:]
+
+	readStats := computeBenchStats(readLatencies)
+	insertStats := computeBenchStats(insertLatencies)
 
 	result := FullBenchmark{
-		Region:          region,
-		DBHost:          dbHost,
-		Iterations:      iterations,
-		ReadAvgMs:       readAvg,
-		ReadMinMs:       readMin,
-		ReadMaxMs:       readMax,
-		InsertAvgMs:     insertAvg,
-		InsertMinMs:     insertMin,
-		InsertMaxMs:     insertMax,
-		ReadLatencies:   readLatencies,
-		InsertLatencies: insertLatencies,
-		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		Region:         region,
+		DBHost:         primary.Host,
+		Iterations:     iterations,
+		Warmup:         warmup,
+		Concurrency:    concurrency,
+		ReadAvgMs:      readStats.Avg,
+		ReadMinMs:      readStats.Min,
+		ReadMaxMs:      readStats.Max,
+		ReadP50Ms:      readStats.P50,
+		ReadP95Ms:      readStats.P95,
+		ReadP99Ms:      readStats.P99,
+		ReadStddevMs:   readStats.Stddev,
+		InsertAvgMs:    insertStats.Avg,
+		InsertMinMs:    insertStats.Min,
+		InsertMaxMs:    insertStats.Max,
+		InsertP50Ms:    insertStats.P50,
+		InsertP95Ms:    insertStats.P95,
+		InsertP99Ms:    insertStats.P99,
+		InsertStddevMs: insertStats.Stddev,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+	}
+	if raw {
+		result.ReadLatencies = readLatencies
+		result.InsertLatencies = insertLatencies
 	}
 
 	jsonResponse(w, result, http.StatusOK)
@@ -508,7 +681,7 @@ func cleanupBenchmarkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM contacts WHERE notes = 'Benchmark test record' OR notes = 'Benchmark'")
+	result, err := pool.Primary().Exec("DELETE FROM contacts WHERE notes = 'Benchmark test record' OR notes = 'Benchmark'")
 	if err != nil {
 		errorResponse(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -558,6 +731,77 @@ func contactsRouter(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// dbMaxOpenConns bounds the primary's connection pool; the admission
+// middleware uses the same number to shed load before requests queue on
+// it instead of failing open.
+const dbMaxOpenConns = 10
+
+// Per-route request deadlines. /health stays short since it's polled
+// frequently and should fail fast; /benchmark/full gets the most room
+// since it runs (warmup+iterations)*2 queries, optionally across concurrency
+// goroutines.
+const (
+	healthTimeout    = 2 * time.Second
+	defaultTimeout   = 5 * time.Second
+	contactsTimeout  = 10 * time.Second
+	benchmarkTimeout = 10 * time.Second
+	fullBenchTimeout = 60 * time.Second
+)
+
+// shuttingDown is set once Shutdown begins, so the admission middleware
+// can reject new work immediately instead of letting it race the drain.
+var shuttingDown int32
+
+// withTimeout bounds r.Context() by d for the lifetime of next's call,
+// so a handler that ignores a slow downstream query doesn't hold a
+// connection (or a client) open indefinitely.
+func withTimeout(d time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// admission sheds load before it reaches the handlers: during shutdown,
+// or once the primary's pool has dbMaxOpenConns connections open (in use
+// or idle), it returns 503 with a
+// Retry-After hint rather than letting the request queue on the pool -
+// the previous behavior of a slow replica silently stalling every
+// handler with no backpressure signal to the caller.
+func admission(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&shuttingDown) == 1 {
+			retryUnavailable(w)
+			return
+		}
+		if stats := pool.Primary().Stats(); stats.OpenConnections >= dbMaxOpenConns {
+			retryUnavailable(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func retryUnavailable(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "2")
+	errorResponse(w, "service unavailable", http.StatusServiceUnavailable)
+}
+
+// getDurationEnv reads key as a Go duration string (e.g. "30s"), falling
+// back to def when unset or unparseable.
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
 func main() {
 	log.Println("Initializing Contacts API...")
 
@@ -571,25 +815,83 @@ func main() {
 	}
 	log.Println("Schema initialized")
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+	mux.Handle("/", withTimeout(defaultTimeout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/" {
 			rootHandler(w, r)
 			return
 		}
 		errorResponse(w, "Not found", http.StatusNotFound)
-	})
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/stats", statsHandler)
-	http.HandleFunc("/contacts", contactsRouter)
-	http.HandleFunc("/contacts/", contactsRouter)
+	})))
+	mux.Handle("/health", withTimeout(healthTimeout, http.HandlerFunc(healthHandler)))
+	mux.Handle("/stats", withTimeout(defaultTimeout, auth.Middleware(authStore, rateLimiter, auth.Scope(auth.ScopeContactsRead),
+		metrics.Middleware(metricsRegistry, "/stats", statsHandler))))
+	mux.Handle("/contacts", withTimeout(contactsTimeout, auth.Middleware(authStore, rateLimiter, auth.MethodScope(map[string]string{
+		http.MethodGet:  auth.ScopeContactsRead,
+		http.MethodPost: auth.ScopeContactsWrite,
+	}), metrics.Middleware(metricsRegistry, "/contacts", contactsRouter))))
+	mux.Handle("/contacts/", withTimeout(contactsTimeout, auth.Middleware(authStore, rateLimiter, auth.MethodScope(map[string]string{
+		http.MethodGet:    auth.ScopeContactsRead,
+		http.MethodPut:    auth.ScopeContactsWrite,
+		http.MethodDelete: auth.ScopeContactsWrite,
+	}), metrics.Middleware(metricsRegistry, "/contacts/", contactsRouter))))
+	mux.Handle("/metrics", withTimeout(defaultTimeout, metrics.Handler(metricsRegistry)))
+
+	// Admin: token issuance/revocation. Gated by ScopeAdmin like any other
+	// scoped route, with one bootstrap exception: while ADMIN_BOOTSTRAP_TOKEN
+	// is set, a request bearing that exact token is let through so the very
+	// first admin token can be minted before any token exists. Unset it (or
+	// rotate it) once steady-state admin tokens are in place.
+	mux.Handle("/admin/tokens", withTimeout(defaultTimeout, auth.AdminOrBootstrap(authStore, rateLimiter, authStore.TokensRouter)))
+	mux.Handle("/admin/tokens/", withTimeout(defaultTimeout, auth.AdminOrBootstrap(authStore, rateLimiter, authStore.TokensRouter)))
 
 	// Benchmark endpoints
-	http.HandleFunc("/benchmark/read", benchmarkReadHandler)
-	http.HandleFunc("/benchmark/insert", benchmarkInsertHandler)
-	http.HandleFunc("/benchmark/full", benchmarkFullHandler)
-	http.HandleFunc("/benchmark/cleanup", cleanupBenchmarkHandler)
+	mux.Handle("/benchmark/read", withTimeout(benchmarkTimeout,
+		auth.Middleware(authStore, rateLimiter, auth.Scope(auth.ScopeBenchmark), benchmarkReadHandler)))
+	mux.Handle("/benchmark/insert", withTimeout(benchmarkTimeout,
+		auth.Middleware(authStore, rateLimiter, auth.Scope(auth.ScopeBenchmark), benchmarkInsertHandler)))
+	mux.Handle("/benchmark/full", withTimeout(fullBenchTimeout,
+		auth.Middleware(authStore, rateLimiter, auth.Scope(auth.ScopeBenchmark), benchmarkFullHandler)))
+	mux.Handle("/benchmark/cleanup", withTimeout(benchmarkTimeout,
+		auth.Middleware(authStore, rateLimiter, auth.Scope(auth.ScopeBenchmark), cleanupBenchmarkHandler)))
+
+	accessLog := accesslog.LoggingHandler(admission(mux), "", os.Stderr)
 
 	port := getEnv("PORT", "8080")
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           accessLog,
+		ReadHeaderTimeout: getDurationEnv("READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       getDurationEnv("READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:      getDurationEnv("WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       getDurationEnv("IDLE_TIMEOUT", 120*time.Second),
+	}
+
+	shutdownComplete := make(chan struct{})
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+		<-sigCh
+		log.Println("Shutdown signal received, draining connections...")
+		atomic.StoreInt32(&shuttingDown, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Graceful shutdown error: %v", err)
+		}
+		if err := pool.Close(); err != nil {
+			log.Printf("Error closing database pool: %v", err)
+		}
+		if err := accessLog.Close(); err != nil {
+			log.Printf("Error closing access log: %v", err)
+		}
+		close(shutdownComplete)
+	}()
+
 	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Server error: %v", err)
+	}
+	<-shutdownComplete
 }
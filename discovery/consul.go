@@ -0,0 +1,218 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ConsulRegistry talks to Consul's HTTP agent/catalog API directly rather
+// than vendoring hashicorp/consul/api, consistent with how the rest of this
+// repo avoids third-party dependencies it can't fetch.
+type ConsulRegistry struct {
+	Addr    string // e.g. http://127.0.0.1:8500
+	Service string // Consul service name backends register under
+	client  *http.Client
+}
+
+func NewConsulRegistry(addr, service string) *ConsulRegistry {
+	return &ConsulRegistry{Addr: addr, Service: service, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+type consulServiceEntry struct {
+	Service struct {
+		ID      string            `json:"ID"`
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Meta    map[string]string `json:"Meta"`
+	} `json:"Service"`
+	Checks []struct {
+		Status string `json:"Status"`
+	} `json:"Checks"`
+}
+
+func (c *ConsulRegistry) Backends(ctx context.Context) ([]Backend, error) {
+	url := fmt.Sprintf("%s/v1/health/service/%s", c.Addr, c.Service)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul: GET %s: %s", url, resp.Status)
+	}
+
+	var entries []consulServiceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	backends := make([]Backend, 0, len(entries))
+	for _, e := range entries {
+		weight, _ := strconv.Atoi(e.Service.Meta["weight"])
+		if weight == 0 {
+			weight = 1
+		}
+		backends = append(backends, Backend{
+			ID:      e.Service.ID,
+			Region:  e.Service.Meta["region"],
+			Address: fmt.Sprintf("%s:%d", e.Service.Address, e.Service.Port),
+			Weight:  weight,
+			Healthy: consulPassing(e.Checks),
+		})
+	}
+	return backends, nil
+}
+
+func consulPassing(checks []struct {
+	Status string `json:"Status"`
+}) bool {
+	for _, chk := range checks {
+		if chk.Status != "passing" {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *ConsulRegistry) Register(ctx context.Context, b Backend, ttl time.Duration) error {
+	host, portStr, err := net.SplitHostPort(b.Address)
+	if err != nil {
+		return err
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"ID":      b.ID,
+		"Name":    c.Service,
+		"Address": host,
+		"Port":    port,
+		"Meta": map[string]string{
+			"region": b.Region,
+			"weight": strconv.Itoa(b.Weight),
+		},
+		"Check": map[string]interface{}{
+			"TTL":                            ttl.String(),
+			"DeregisterCriticalServiceAfter": (ttl * 3).String(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/agent/service/register", c.Addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul: register %s: %s", b.ID, resp.Status)
+	}
+
+	// Passing the TTL check is what keeps Consul's health/service view of
+	// this backend from flipping to critical between Register calls.
+	passURL := fmt.Sprintf("%s/v1/agent/check/pass/service:%s", c.Addr, b.ID)
+	passReq, err := http.NewRequestWithContext(ctx, http.MethodPut, passURL, nil)
+	if err != nil {
+		return err
+	}
+	passResp, err := c.client.Do(passReq)
+	if err != nil {
+		return err
+	}
+	defer passResp.Body.Close()
+	return nil
+}
+
+func (c *ConsulRegistry) Deregister(ctx context.Context, id string) error {
+	url := fmt.Sprintf("%s/v1/agent/service/deregister/%s", c.Addr, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul: deregister %s: %s", id, resp.Status)
+	}
+	return nil
+}
+
+// Watch polls Consul's health/service endpoint every 5 seconds (a plain
+// GET; no blocking-query index or wait is used) and diffs successive
+// snapshots into add/remove/health-change events.
+func (c *ConsulRegistry) Watch(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		previous := map[string]Backend{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			current, err := c.Backends(ctx)
+			if err != nil {
+				time.Sleep(2 * time.Second)
+				continue
+			}
+
+			seen := map[string]bool{}
+			for _, b := range current {
+				seen[b.ID] = true
+				prev, ok := previous[b.ID]
+				switch {
+				case !ok:
+					if !sendEvent(ctx, events, Event{Type: EventAdded, Backend: b}) {
+						return
+					}
+				case prev.Healthy != b.Healthy:
+					if !sendEvent(ctx, events, Event{Type: EventHealthChanged, Backend: b}) {
+						return
+					}
+				}
+				previous[b.ID] = b
+			}
+			for id, b := range previous {
+				if !seen[id] {
+					if !sendEvent(ctx, events, Event{Type: EventRemoved, Backend: b}) {
+						return
+					}
+					delete(previous, id)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,202 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// EtcdRegistry stores backends as keys under Prefix using etcd v3's
+// gRPC-gateway JSON/REST API (/v3/kv/..., /v3/lease/...), so it needs no
+// etcd client library - just net/http, matching the rest of this repo's
+// minimal-dependency style.
+type EtcdRegistry struct {
+	Addr   string // e.g. http://127.0.0.1:2379
+	Prefix string // e.g. "/edgeproxy/backends/"
+	client *http.Client
+
+	leases map[string]int64 // backend ID -> lease ID, for renewal
+}
+
+func NewEtcdRegistry(addr, prefix string) *EtcdRegistry {
+	return &EtcdRegistry{
+		Addr:   addr,
+		Prefix: prefix,
+		client: &http.Client{Timeout: 5 * time.Second},
+		leases: map[string]int64{},
+	}
+}
+
+func (e *EtcdRegistry) post(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Addr+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd: POST %s: %s", path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (e *EtcdRegistry) Backends(ctx context.Context) ([]Backend, error) {
+	var resp struct {
+		Kvs []struct {
+			Value string `json:"value"` // base64-encoded JSON Backend
+		} `json:"kvs"`
+	}
+	err := e.post(ctx, "/v3/kv/range", map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(e.Prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(prefixRangeEnd(e.Prefix)),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	backends := make([]Backend, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		raw, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			continue
+		}
+		var b Backend
+		if json.Unmarshal(raw, &b) == nil {
+			backends = append(backends, b)
+		}
+	}
+	return backends, nil
+}
+
+func (e *EtcdRegistry) Register(ctx context.Context, b Backend, ttl time.Duration) error {
+	b.Healthy = true
+
+	leaseID, ok := e.leases[b.ID]
+	if !ok {
+		var grantResp struct {
+			ID string `json:"ID"`
+		}
+		if err := e.post(ctx, "/v3/lease/grant", map[string]interface{}{
+			"TTL": int64(ttl.Seconds()),
+		}, &grantResp); err != nil {
+			return err
+		}
+		leaseID, _ = strconv.ParseInt(grantResp.ID, 10, 64)
+		e.leases[b.ID] = leaseID
+	} else {
+		// Renew the existing lease rather than granting a new one every
+		// heartbeat, so the key doesn't churn through different lease IDs.
+		if err := e.post(ctx, "/v3/lease/keepalive", map[string]interface{}{
+			"ID": strconv.FormatInt(leaseID, 10),
+		}, nil); err != nil {
+			delete(e.leases, b.ID)
+			return err
+		}
+	}
+
+	value, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	return e.post(ctx, "/v3/kv/put", map[string]interface{}{
+		"key":   base64.StdEncoding.EncodeToString([]byte(e.Prefix + b.ID)),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": strconv.FormatInt(leaseID, 10),
+	}, nil)
+}
+
+func (e *EtcdRegistry) Deregister(ctx context.Context, id string) error {
+	delete(e.leases, id)
+	return e.post(ctx, "/v3/kv/deleterange", map[string]string{
+		"key": base64.StdEncoding.EncodeToString([]byte(e.Prefix + id)),
+	}, nil)
+}
+
+// Watch polls the key range every few seconds rather than using etcd's
+// native gRPC-streaming /v3/watch endpoint, which isn't practical to speak
+// over plain HTTP/1.1 without a gRPC or websocket-gateway client.
+func (e *EtcdRegistry) Watch(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		previous := map[string]Backend{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			current, err := e.Backends(ctx)
+			if err == nil {
+				seen := map[string]bool{}
+				for _, b := range current {
+					seen[b.ID] = true
+					prev, ok := previous[b.ID]
+					switch {
+					case !ok:
+						if !sendEvent(ctx, events, Event{Type: EventAdded, Backend: b}) {
+							return
+						}
+					case prev.Healthy != b.Healthy:
+						if !sendEvent(ctx, events, Event{Type: EventHealthChanged, Backend: b}) {
+							return
+						}
+					}
+					previous[b.ID] = b
+				}
+				for id, b := range previous {
+					if !seen[id] {
+						if !sendEvent(ctx, events, Event{Type: EventRemoved, Backend: b}) {
+							return
+						}
+						delete(previous, id)
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// prefixRangeEnd computes etcd's canonical "end of prefix" key: the prefix
+// with its last byte incremented, which is how a range query matches every
+// key sharing that prefix.
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
@@ -0,0 +1,33 @@
+package discovery
+
+import (
+	"context"
+	"time"
+)
+
+// Heartbeat self-registers b with reg and keeps refreshing it at interval
+// (which should be well under ttl) until ctx is canceled, at which point it
+// deregisters b so the proxy drains it immediately instead of waiting out
+// the TTL. A backend process calls this once at startup, typically in its
+// own goroutine, in place of a one-shot /register call.
+func Heartbeat(ctx context.Context, reg Registry, b Backend, ttl, interval time.Duration) error {
+	if err := reg.Register(ctx, b, ttl); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return reg.Deregister(deregisterCtx, b.ID)
+		case <-ticker.C:
+			if err := reg.Register(ctx, b, ttl); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Pool is an atomically-swapped snapshot of healthy backends, kept in sync
+// with a Registry's Watch stream. Reads never block on the watch loop and
+// never observe a partially-applied update.
+type Pool struct {
+	reg     Registry
+	current atomic.Value // []Backend
+}
+
+// NewPool seeds the pool from reg.Backends and returns it; call Run in its
+// own goroutine to keep it updated.
+func NewPool(ctx context.Context, reg Registry) (*Pool, error) {
+	p := &Pool{reg: reg}
+
+	backends, err := reg.Backends(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: seed pool: %w", err)
+	}
+	p.current.Store(healthyOnly(backends))
+	return p, nil
+}
+
+// Backends returns the current healthy backend snapshot. Safe for
+// concurrent use by request-handling goroutines.
+func (p *Pool) Backends() []Backend {
+	v, _ := p.current.Load().([]Backend)
+	return v
+}
+
+// Run watches the registry and applies add/remove/health-change events to
+// the pool until ctx is canceled. It is meant to run in its own goroutine
+// for the lifetime of the proxy process.
+func (p *Pool) Run(ctx context.Context) error {
+	events, err := p.reg.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("discovery: watch: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			p.apply(ev)
+		}
+	}
+}
+
+func (p *Pool) apply(ev Event) {
+	backends := p.Backends()
+	next := make([]Backend, 0, len(backends)+1)
+
+	found := false
+	for _, b := range backends {
+		if b.ID != ev.Backend.ID {
+			next = append(next, b)
+			continue
+		}
+		found = true
+		switch ev.Type {
+		case EventRemoved:
+			// dropped: graceful draining means in-flight requests to this
+			// backend finish, but it stops receiving new ones immediately.
+		case EventAdded, EventHealthChanged:
+			if ev.Backend.Healthy {
+				next = append(next, ev.Backend)
+			}
+		}
+	}
+	if !found && ev.Type != EventRemoved && ev.Backend.Healthy {
+		next = append(next, ev.Backend)
+	}
+
+	p.current.Store(next)
+}
+
+func healthyOnly(backends []Backend) []Backend {
+	out := make([]Backend, 0, len(backends))
+	for _, b := range backends {
+		if b.Healthy {
+			out = append(out, b)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+// Package discovery defines a backend-discovery abstraction so edgeProxy can
+// source its load-balancer pool from a dynamic registry (Consul, etcd,
+// Kubernetes Endpoints) instead of a static backend list, and react to
+// add/remove/health-change events without a restart.
+package discovery
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is one upstream instance a Registry knows about. Region and
+// Weight mirror the metadata the mock backend already exposes via
+// handleInfo, so a proxy can route by locality or capacity.
+type Backend struct {
+	ID      string
+	Region  string
+	Address string
+	Weight  int
+	Healthy bool
+}
+
+// EventType identifies what changed about a Backend in a Watch event.
+type EventType int
+
+const (
+	EventAdded EventType = iota
+	EventRemoved
+	EventHealthChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdded:
+		return "added"
+	case EventRemoved:
+		return "removed"
+	case EventHealthChanged:
+		return "health_changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a single change a Registry observed, delivered over the channel
+// returned by Watch.
+type Event struct {
+	Type    EventType
+	Backend Backend
+}
+
+// Registry is the interface every discovery backend (Consul, etcd,
+// Kubernetes Endpoints) implements. Register is how a backend process
+// self-registers on startup and renews its TTL; Watch is how the proxy
+// learns about changes without polling.
+type Registry interface {
+	// Backends returns the current known set, used to seed a Pool at
+	// startup before the first Watch event arrives.
+	Backends(ctx context.Context) ([]Backend, error)
+
+	// Register adds or refreshes b's entry. Callers drive their own TTL
+	// heartbeat by calling Register repeatedly (see Heartbeat).
+	Register(ctx context.Context, b Backend, ttl time.Duration) error
+
+	// Deregister removes id immediately, used for graceful draining when a
+	// backend shuts down rather than waiting out its TTL.
+	Deregister(ctx context.Context, id string) error
+
+	// Watch streams add/remove/health-change events until ctx is canceled,
+	// at which point the returned channel is closed.
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// sendEvent delivers ev on events, or gives up if ctx is canceled first.
+// Every Watch implementation's diff loop sends on an unbuffered channel
+// whose only consumer (Pool.Run) stops reading as soon as ctx is done, so
+// sends must race ctx.Done() too - otherwise a send that loses that race
+// blocks forever and leaks the watch goroutine. Returns false when ctx won
+// the race, so the caller knows to stop rather than keep diffing.
+func sendEvent(ctx context.Context, events chan<- Event, ev Event) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
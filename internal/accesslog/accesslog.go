@@ -0,0 +1,124 @@
+// Package accesslog wraps an http.Handler with a configurable, Apache
+// mod_log_config-style access logger, so routes keep their existing
+// log.Println-based startup logging while every request also gets one
+// structured line - a drop-in replacement for ad-hoc per-handler logging.
+package accesslog
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultFormat mirrors Apache's common log format plus the %D latency
+// directive and the request-ID header every proxied request already
+// carries, with the FLY_REGION environment variable appended so logs from
+// different edge nodes are distinguishable once aggregated.
+const DefaultFormat = `%h %l %u %t "%r" %>s %b %D %{X-Request-ID}i %{FLY_REGION}e`
+
+// flushInterval is how often the background goroutine flushes the
+// buffered writer, bounding how long a line can sit unwritten if the
+// process is killed before the next request arrives.
+const flushInterval = time.Second
+
+// Handler logs one line per request in the format it was constructed with,
+// writing through a buffered io.Writer so most requests only append to an
+// in-memory buffer. A request still waits on the underlying writer when its
+// line fills the buffer, or while a periodic flush holds the lock.
+type Handler struct {
+	next       http.Handler
+	directives []directive
+
+	mu     sync.Mutex
+	bw     *bufio.Writer
+	done   chan struct{}
+	closed bool
+}
+
+// LoggingHandler parses format once at construction into a slice of
+// directive funcs and returns a *Handler wrapping h, writing one access-log
+// line per request to w. An empty format falls back to the LOG_FORMAT
+// environment variable, then to DefaultFormat. The concrete type (rather
+// than http.Handler) is returned so callers can defer/Close it on shutdown
+// and flush whatever's still buffered.
+func LoggingHandler(h http.Handler, format string, w io.Writer) *Handler {
+	if format == "" {
+		format = os.Getenv("LOG_FORMAT")
+	}
+	if format == "" {
+		format = DefaultFormat
+	}
+
+	lh := &Handler{
+		next:       h,
+		directives: compile(format),
+		bw:         bufio.NewWriter(w),
+		done:       make(chan struct{}),
+	}
+	go lh.flushLoop()
+	return lh
+}
+
+func (lh *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+
+	lh.next.ServeHTTP(rec, r)
+
+	rr := &record{
+		req:        r,
+		status:     rec.status,
+		bytes:      rec.bytes,
+		start:      start,
+		duration:   time.Since(start),
+		respHeader: w.Header(),
+	}
+	lh.writeLine(rr)
+}
+
+func (lh *Handler) writeLine(rr *record) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	for _, d := range lh.directives {
+		lh.bw.WriteString(d(rr))
+	}
+	lh.bw.WriteByte('\n')
+}
+
+func (lh *Handler) flushLoop() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lh.done:
+			lh.mu.Lock()
+			lh.bw.Flush()
+			lh.mu.Unlock()
+			return
+		case <-ticker.C:
+			lh.mu.Lock()
+			lh.bw.Flush()
+			lh.mu.Unlock()
+		}
+	}
+}
+
+// Close stops the background flush goroutine after one final flush. Safe
+// to call more than once.
+func (lh *Handler) Close() error {
+	lh.mu.Lock()
+	if lh.closed {
+		lh.mu.Unlock()
+		return nil
+	}
+	lh.closed = true
+	err := lh.bw.Flush()
+	lh.mu.Unlock()
+
+	close(lh.done)
+	return err
+}
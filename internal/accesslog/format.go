@@ -0,0 +1,168 @@
+package accesslog
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// directive renders one piece of an access-log line from a completed
+// request's record - either a literal fragment of the format string or a
+// computed field like %h or %D.
+type directive func(rr *record) string
+
+// apacheTimeLayout is strftime "%d/%b/%Y:%H:%M:%S %z" wrapped in brackets,
+// the exact rendering Apache's %t directive produces.
+const apacheTimeLayout = "[02/Jan/2006:15:04:05 -0700]"
+
+// compile parses format once into a slice of directive funcs, so logging a
+// request at runtime never re-parses the format string.
+func compile(format string) []directive {
+	var out []directive
+	i := 0
+	for i < len(format) {
+		if format[i] != '%' {
+			j := i
+			for j < len(format) && format[j] != '%' {
+				j++
+			}
+			out = append(out, literal(format[i:j]))
+			i = j
+			continue
+		}
+
+		i++ // consume '%'
+		if i >= len(format) {
+			out = append(out, literal("%"))
+			break
+		}
+
+		if format[i] == '>' {
+			// "%>s" is Apache's "final status" marker; this package only
+			// ever has the final status, so '>' is accepted and ignored.
+			i++
+			if i >= len(format) {
+				break
+			}
+		}
+
+		if format[i] == '{' {
+			end := strings.IndexByte(format[i:], '}')
+			if end == -1 {
+				out = append(out, literal(format[i:]))
+				break
+			}
+			key := format[i+1 : i+end]
+			i += end + 1
+			if i >= len(format) {
+				out = append(out, literal(fmt.Sprintf("%%{%s}", key)))
+				break
+			}
+			verb := format[i]
+			i++
+			out = append(out, headerOrEnvDirective(key, verb))
+			continue
+		}
+
+		verb := format[i]
+		i++
+		out = append(out, simpleDirective(verb))
+	}
+	return out
+}
+
+func literal(s string) directive {
+	return func(*record) string { return s }
+}
+
+func simpleDirective(verb byte) directive {
+	switch verb {
+	case 'h':
+		return remoteHost
+	case 'l', 'u':
+		// %l (remote logname via identd) and %u (authenticated user) are
+		// never available without deeper integration; Apache's own
+		// convention for "unknown" is a literal dash.
+		return literal("-")
+	case 't':
+		return requestTime
+	case 'r':
+		return requestLine
+	case 's':
+		return status
+	case 'b':
+		return byteCount
+	case 'D':
+		return microseconds
+	case 'T':
+		return seconds
+	case '%':
+		return literal("%")
+	default:
+		return literal("%" + string(verb))
+	}
+}
+
+func headerOrEnvDirective(key string, verb byte) directive {
+	switch verb {
+	case 'i':
+		return func(rr *record) string { return headerOrDash(rr.req.Header, key) }
+	case 'o':
+		return func(rr *record) string { return headerOrDash(rr.respHeader, key) }
+	case 'e':
+		return func(*record) string {
+			if v := os.Getenv(key); v != "" {
+				return v
+			}
+			return "-"
+		}
+	default:
+		return literal(fmt.Sprintf("%%{%s}%c", key, verb))
+	}
+}
+
+func headerOrDash(h http.Header, key string) string {
+	if v := h.Get(key); v != "" {
+		return v
+	}
+	return "-"
+}
+
+func remoteHost(rr *record) string {
+	host, _, err := net.SplitHostPort(rr.req.RemoteAddr)
+	if err != nil {
+		return rr.req.RemoteAddr
+	}
+	return host
+}
+
+func requestTime(rr *record) string {
+	return rr.start.Format(apacheTimeLayout)
+}
+
+func requestLine(rr *record) string {
+	return fmt.Sprintf("%s %s %s", rr.req.Method, rr.req.URL.RequestURI(), rr.req.Proto)
+}
+
+func status(rr *record) string {
+	return strconv.Itoa(rr.status)
+}
+
+func byteCount(rr *record) string {
+	if rr.bytes == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(rr.bytes, 10)
+}
+
+func microseconds(rr *record) string {
+	return strconv.FormatInt(rr.duration.Microseconds(), 10)
+}
+
+func seconds(rr *record) string {
+	return strconv.FormatInt(int64(rr.duration/time.Second), 10)
+}
@@ -0,0 +1,45 @@
+package accesslog
+
+import (
+	"net/http"
+	"time"
+)
+
+// record is the per-request data every directive func reads from; it is
+// assembled once after the handler returns and passed to each directive in
+// turn rather than recomputed per-directive.
+type record struct {
+	req        *http.Request
+	status     int
+	bytes      int64
+	start      time.Time
+	duration   time.Duration
+	respHeader http.Header
+}
+
+// statusRecorder captures the status code and byte count a handler wrote,
+// since http.ResponseWriter exposes neither after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
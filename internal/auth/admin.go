@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CreateTokenRequest struct {
+	Owner  string   `json:"owner"`
+	Scopes []string `json:"scopes"`
+}
+
+type CreateTokenResponse struct {
+	ID     int      `json:"id"`
+	Token  string   `json:"token"`
+	Owner  string   `json:"owner"`
+	Scopes []string `json:"scopes"`
+}
+
+// TokensRouter dispatches POST /admin/tokens and DELETE /admin/tokens/{id},
+// mirroring contactsRouter's style of one router per resource shared
+// across both mux patterns.
+func (s *Store) TokensRouter(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/admin/tokens/") && len(r.URL.Path) > len("/admin/tokens/") {
+		if r.Method == http.MethodDelete {
+			s.deleteTokenHandler(w, r)
+			return
+		}
+		errorJSON(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.createTokenHandler(w, r)
+	default:
+		errorJSON(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createTokenHandler returns the plaintext token exactly once; only its
+// SHA-256 hash is ever persisted.
+func (s *Store) createTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorJSON(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Owner == "" || len(req.Scopes) == 0 {
+		errorJSON(w, "owner and scopes are required", http.StatusBadRequest)
+		return
+	}
+
+	plaintext, id, err := s.CreateToken(req.Owner, req.Scopes)
+	if err != nil {
+		errorJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, CreateTokenResponse{ID: id, Token: plaintext, Owner: req.Owner, Scopes: req.Scopes}, http.StatusCreated)
+}
+
+func (s *Store) deleteTokenHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/admin/tokens/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		errorJSON(w, "invalid token id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.DeleteToken(id); err != nil {
+		errorJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
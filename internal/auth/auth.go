@@ -0,0 +1,126 @@
+// Package auth adds bearer-token authentication and per-token rate
+// limiting to the Contacts API: a tokens table holding only SHA-256
+// hashes of issued tokens, an HTTP middleware that checks scope and
+// enforces a token-bucket limit, and admin endpoints to mint and revoke
+// tokens. Set AUTH_DISABLED=true to bypass all of it during rollout.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Schema is executed alongside the contacts table in initSchema.
+const Schema = `
+CREATE TABLE IF NOT EXISTS tokens (
+	id SERIAL PRIMARY KEY,
+	token_hash BYTEA NOT NULL UNIQUE,
+	owner TEXT NOT NULL,
+	scopes TEXT[] NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMPTZ,
+	revoked BOOLEAN NOT NULL DEFAULT FALSE
+);
+`
+
+// ErrNotFound is returned by Lookup when no live token matches.
+var ErrNotFound = errors.New("auth: token not found")
+
+// DB is the subset of *sql.DB that Store needs, satisfied directly by
+// *sql.DB and by wrappers that embed it, such as metrics.Instrumented.
+type DB interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Token is a row from the tokens table, minus the hash.
+type Token struct {
+	ID     int
+	Owner  string
+	Scopes []string
+}
+
+// Store issues and validates tokens against the tokens table.
+type Store struct {
+	db DB
+}
+
+func NewStore(db DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateToken generates a new random token, persists only its SHA-256
+// hash, and returns the plaintext - the only time it is ever available.
+func (s *Store) CreateToken(owner string, scopes []string) (plaintext string, id int, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", 0, err
+	}
+	plaintext = hex.EncodeToString(raw)
+	hash := sha256.Sum256([]byte(plaintext))
+
+	err = s.db.QueryRow(`
+		INSERT INTO tokens (token_hash, owner, scopes)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, hash[:], owner, pq.Array(scopes)).Scan(&id)
+	if err != nil {
+		return "", 0, err
+	}
+	return plaintext, id, nil
+}
+
+// DeleteToken removes a token outright; the id can no longer authenticate.
+func (s *Store) DeleteToken(id int) error {
+	_, err := s.db.Exec("DELETE FROM tokens WHERE id = $1", id)
+	return err
+}
+
+// Lookup resolves the token presented on the wire to its scopes, hashing
+// it and comparing against the stored hash so the plaintext is never
+// persisted or logged. Revoked tokens are treated as not found.
+func (s *Store) Lookup(plaintext string) (*Token, error) {
+	hash := sha256.Sum256([]byte(plaintext))
+
+	var t Token
+	var scopes pq.StringArray
+	err := s.db.QueryRow(`
+		SELECT id, owner, scopes FROM tokens
+		WHERE token_hash = $1 AND revoked = false
+	`, hash[:]).Scan(&t.ID, &t.Owner, &scopes)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Scopes = scopes
+	return &t, nil
+}
+
+// Touch updates last_used_at for id. Callers invoke this in a goroutine
+// (see TouchAsync) so the request path never waits on it.
+func (s *Store) Touch(id int) error {
+	_, err := s.db.Exec("UPDATE tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return err
+}
+
+// TouchAsync fires Touch in the background and drops any error; a missed
+// last_used_at update isn't worth failing or delaying the request for.
+func (s *Store) TouchAsync(id int) {
+	go s.Touch(id)
+}
+
+func hasScope(scopes []string, want string) bool {
+	for _, s := range scopes {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func writeJSON(w http.ResponseWriter, data interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func errorJSON(w http.ResponseWriter, message string, status int) {
+	writeJSON(w, map[string]string{"error": message}, status)
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	errorJSON(w, message, http.StatusUnauthorized)
+}
+
+func forbidden(w http.ResponseWriter, message string) {
+	errorJSON(w, message, http.StatusForbidden)
+}
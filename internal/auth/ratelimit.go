@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultQPS is used for any scope without a RATE_LIMIT_<SCOPE>_QPS
+// override.
+const defaultQPS = 10.0
+
+// bucket is a classic token bucket: tokens refill continuously at qps and
+// cap at qps (so burst == the scope's per-second rate).
+type bucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	lastFill time.Time
+}
+
+func (b *bucket) allow(qps float64) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.lastFill).Seconds() * qps
+	if b.tokens > qps {
+		b.tokens = qps
+	}
+	b.lastFill = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / qps * float64(time.Second))
+}
+
+// Limiter is a token-bucket rate limiter keyed by (token id, scope), so a
+// token's budget on contacts:read doesn't steal from its budget on
+// benchmark. Per-scope QPS is read from RATE_LIMIT_<SCOPE>_QPS env vars,
+// e.g. RATE_LIMIT_CONTACTS_READ_QPS=50.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	qps     map[string]float64
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: map[string]*bucket{},
+		qps:     map[string]float64{},
+	}
+}
+
+// Allow reports whether a request for tokenID against scope may proceed.
+// When it may not, the returned duration is how long the caller should
+// tell the client to wait before retrying.
+func (l *Limiter) Allow(tokenID int, scope string) (bool, time.Duration) {
+	key := fmt.Sprintf("%d:%s", tokenID, scope)
+
+	l.mu.Lock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.qpsFor(scope), lastFill: time.Now()}
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+
+	return b.allow(l.qpsFor(scope))
+}
+
+func (l *Limiter) qpsFor(scope string) float64 {
+	l.mu.Lock()
+	if qps, ok := l.qps[scope]; ok {
+		l.mu.Unlock()
+		return qps
+	}
+	l.mu.Unlock()
+
+	qps := defaultQPS
+	if v := os.Getenv(envName(scope)); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil && parsed > 0 {
+			qps = parsed
+		}
+	}
+
+	l.mu.Lock()
+	l.qps[scope] = qps
+	l.mu.Unlock()
+	return qps
+}
+
+// envName turns a scope like "contacts:read" into RATE_LIMIT_CONTACTS_READ_QPS.
+func envName(scope string) string {
+	clean := strings.NewReplacer(":", "_", "-", "_").Replace(scope)
+	return "RATE_LIMIT_" + strings.ToUpper(clean) + "_QPS"
+}
@@ -0,0 +1,84 @@
+// Package dbpool routes reads across a primary and a set of round-robin
+// replicas, so handlers that can tolerate replication lag don't all land
+// on the same host the writes go to.
+package dbpool
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/andrebassi/edgeproxy/internal/metrics"
+)
+
+// Eventual and Strong are the consistency levels Pick understands. Any
+// other value (including "") is treated as Strong.
+const (
+	Eventual = "eventual"
+	Strong   = "strong"
+)
+
+// Handle is one host's connection pool plus the host label it reports in
+// API responses, so callers can see which host actually served a query.
+type Handle struct {
+	*metrics.Instrumented
+	Host string
+}
+
+// Open dials dsn, wraps it for metrics, and labels it host.
+func Open(dsn, host string, reg *metrics.Registry) (*Handle, error) {
+	raw, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := raw.Ping(); err != nil {
+		return nil, err
+	}
+	return &Handle{Instrumented: metrics.NewInstrumented(raw, reg), Host: host}, nil
+}
+
+// Pool is a primary handle plus zero or more read replicas.
+type Pool struct {
+	primary  *Handle
+	replicas []*Handle
+	next     uint64
+}
+
+func New(primary *Handle, replicas []*Handle) *Pool {
+	return &Pool{primary: primary, replicas: replicas}
+}
+
+// Primary always returns the writable handle.
+func (p *Pool) Primary() *Handle {
+	return p.primary
+}
+
+// Pick returns the handle to use for a query at the given consistency
+// level. Eventual round-robins across replicas and falls back to the
+// primary when none are configured; anything else (including Strong)
+// returns the primary.
+func (p *Pool) Pick(consistency string) *Handle {
+	if consistency != Eventual || len(p.replicas) == 0 {
+		return p.primary
+	}
+	i := atomic.AddUint64(&p.next, 1)
+	return p.replicas[i%uint64(len(p.replicas))]
+}
+
+// Close closes the primary and every replica, carrying on past a failure
+// so every handle is closed, and returns the first error (the primary's,
+// when it failed).
+func (p *Pool) Close() error {
+	var errs []error
+	if err := p.primary.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, r := range p.replicas {
+		if err := r.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
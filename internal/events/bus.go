@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Publisher forwards events to an external sink - NATS, Kafka, Redis
+// Streams, or anything else. Implementations must not block Publish for
+// long; the bus already isolates slow sinks with a bounded queue, but a
+// Publish call that hangs forever still stalls that sink's delivery
+// goroutine.
+type Publisher interface {
+	Publish(ctx context.Context, ev Event) error
+}
+
+// subscriberBufferSize bounds how many undelivered events a slow
+// subscriber or sink can accumulate before the bus starts dropping its
+// oldest queued event to make room for the newest one.
+const subscriberBufferSize = 256
+
+// Bus fans out published events to in-process subscribers and external
+// Publishers. Publish never blocks the caller: delivery to each
+// destination goes through its own bounded, drop-oldest queue.
+type Bus struct {
+	mu      sync.Mutex
+	subs    []*subscriber
+	dropped uint64 // events dropped across all destinations, for diagnostics
+}
+
+type subscriber struct {
+	ch     chan Event
+	cancel context.CancelFunc
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe returns a channel of events and a cancel func to stop
+// receiving. The channel is closed once cancel is called or ctx is done.
+func (b *Bus) Subscribe(ctx context.Context) (<-chan Event, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &subscriber{ch: make(chan Event, subscriberBufferSize), cancel: cancel}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.remove(sub)
+		close(sub.ch)
+	}()
+
+	return sub.ch, cancel
+}
+
+func (b *Bus) remove(target *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.subs {
+		if s == target {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// AttachSink wires an external Publisher into the bus: every event is
+// forwarded to pub.Publish from its own goroutine, through the same
+// bounded drop-oldest queue subscribers use, so a stalled NATS/Kafka/Redis
+// connection can't apply backpressure to request handling.
+func (b *Bus) AttachSink(ctx context.Context, pub Publisher) {
+	events, cancel := b.Subscribe(ctx)
+	go func() {
+		defer cancel()
+		for ev := range events {
+			// Best-effort: a sink error is swallowed here rather than
+			// surfaced to the publisher, since sinks are diagnostic, not
+			// part of the request's correctness.
+			_ = pub.Publish(ctx, ev)
+		}
+	}()
+}
+
+// Publish delivers ev to every current subscriber. If a subscriber's queue
+// is full, its oldest queued event is dropped to make room - publishers on
+// the hot request path must never block on a slow consumer.
+func (b *Bus) Publish(ev Event) {
+	b.mu.Lock()
+	subs := make([]*subscriber, len(b.subs))
+	copy(subs, b.subs)
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		select {
+		case s.ch <- ev:
+		default:
+			// Queue full: drop the oldest, then retry once. If the
+			// subscriber is draining concurrently this can race and still
+			// miss, which is fine - drop-oldest is a best-effort policy.
+			select {
+			case <-s.ch:
+				b.mu.Lock()
+				b.dropped++
+				b.mu.Unlock()
+			default:
+			}
+			select {
+			case s.ch <- ev:
+			default:
+				b.mu.Lock()
+				b.dropped++
+				b.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Dropped returns how many events have been dropped across all
+// subscribers and sinks since the bus was created.
+func (b *Bus) Dropped() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
@@ -0,0 +1,42 @@
+// Package events is a typed pub/sub bus for backend lifecycle and request
+// lifecycle events (backend_up/down, request_started/completed,
+// circuit_opened). In-process subscribers and external sinks (NATS, Kafka,
+// Redis Streams) both sit behind a bounded, drop-oldest buffer so a slow
+// consumer degrades by losing events, never by blocking the request path
+// that published them.
+package events
+
+import "time"
+
+// Type identifies what happened. String values, not iota, so external
+// sinks (NATS subjects, Kafka keys, log lines) get a stable wire format for
+// free.
+type Type string
+
+const (
+	BackendUp        Type = "backend_up"
+	BackendDown      Type = "backend_down"
+	RequestStarted   Type = "request_started"
+	RequestCompleted Type = "request_completed"
+	CircuitOpened    Type = "circuit_opened"
+)
+
+// Event is one occurrence on the bus. Fields not relevant to Type are left
+// zero - e.g. StatusCode is meaningless on a BackendUp event.
+type Event struct {
+	Type      Type      `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	BackendID string    `json:"backend_id,omitempty"`
+	Region    string    `json:"region,omitempty"`
+
+	// RequestID correlates RequestStarted and RequestCompleted events for
+	// the same request.
+	RequestID  string        `json:"request_id,omitempty"`
+	Method     string        `json:"method,omitempty"`
+	Path       string        `json:"path,omitempty"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+
+	// Reason carries the CircuitOpened trigger (e.g. "5 consecutive 5xx").
+	Reason string `json:"reason,omitempty"`
+}
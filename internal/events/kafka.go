@@ -0,0 +1,153 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// KafkaPublisher produces events onto a topic using the wire-level Kafka
+// protocol (Produce API v0, message format v0) directly over a raw TCP
+// connection, since no Kafka client is vendored in this repo. It targets a
+// single broker and partition, which is enough for an async audit sink -
+// a real multi-partition producer would need metadata/partitioner support
+// this does not attempt.
+type KafkaPublisher struct {
+	Addr      string // broker host:port
+	Topic     string
+	Partition int32
+	ClientID  string
+
+	mu            sync.Mutex
+	conn          net.Conn
+	correlationID int32
+}
+
+func NewKafkaPublisher(addr, topic string) *KafkaPublisher {
+	return &KafkaPublisher{Addr: addr, Topic: topic, ClientID: "edgeproxy"}
+}
+
+func (p *KafkaPublisher) connect() error {
+	if p.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", p.Addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	return nil
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, ev Event) error {
+	value, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.connect(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		p.conn.SetDeadline(deadline)
+	}
+
+	p.correlationID++
+	req := p.buildProduceRequest(value, p.correlationID)
+
+	if _, err := p.conn.Write(req); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+
+	// The broker always replies; read and discard the length-prefixed
+	// response so the connection stays framed for the next Produce call.
+	var size int32
+	if err := binary.Read(p.conn, binary.BigEndian, &size); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, p.conn, int64(size)); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+// buildProduceRequest encodes a single-message ProduceRequest (v0) against
+// p.Topic/p.Partition, with the length-prefixed request header already
+// included.
+func (p *KafkaPublisher) buildProduceRequest(value []byte, correlationID int32) []byte {
+	message := encodeKafkaMessageV0(nil, value)
+
+	messageSet := &bytes.Buffer{}
+	binary.Write(messageSet, binary.BigEndian, int64(0)) // offset, ignored by the broker on produce
+	binary.Write(messageSet, binary.BigEndian, int32(len(message)))
+	messageSet.Write(message)
+
+	body := &bytes.Buffer{}
+	binary.Write(body, binary.BigEndian, int16(1))    // RequiredAcks: leader only
+	binary.Write(body, binary.BigEndian, int32(5000)) // Timeout ms
+	binary.Write(body, binary.BigEndian, int32(1))    // one topic
+	writeKafkaString(body, p.Topic)
+	binary.Write(body, binary.BigEndian, int32(1)) // one partition
+	binary.Write(body, binary.BigEndian, p.Partition)
+	binary.Write(body, binary.BigEndian, int32(messageSet.Len()))
+	body.Write(messageSet.Bytes())
+
+	header := &bytes.Buffer{}
+	binary.Write(header, binary.BigEndian, int16(0)) // ApiKey: Produce
+	binary.Write(header, binary.BigEndian, int16(0)) // ApiVersion
+	binary.Write(header, binary.BigEndian, correlationID)
+	writeKafkaString(header, p.ClientID)
+
+	full := &bytes.Buffer{}
+	binary.Write(full, binary.BigEndian, int32(header.Len()+body.Len()))
+	full.Write(header.Bytes())
+	full.Write(body.Bytes())
+	return full.Bytes()
+}
+
+// encodeKafkaMessageV0 builds a single message format v0 record:
+// Crc32 | Magic(0) | Attributes(0) | Key | Value.
+func encodeKafkaMessageV0(key, value []byte) []byte {
+	body := &bytes.Buffer{}
+	body.WriteByte(0) // magic byte: message format v0
+	body.WriteByte(0) // attributes: no compression
+	writeKafkaBytes(body, key)
+	writeKafkaBytes(body, value)
+
+	crc := crc32.ChecksumIEEE(body.Bytes())
+	out := &bytes.Buffer{}
+	binary.Write(out, binary.BigEndian, crc)
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func writeKafkaString(w *bytes.Buffer, s string) {
+	binary.Write(w, binary.BigEndian, int16(len(s)))
+	w.WriteString(s)
+}
+
+// writeKafkaBytes writes a nullable byte array: length -1 for nil, as the
+// protocol requires for an absent message key.
+func writeKafkaBytes(w *bytes.Buffer, b []byte) {
+	if b == nil {
+		binary.Write(w, binary.BigEndian, int32(-1))
+		return
+	}
+	binary.Write(w, binary.BigEndian, int32(len(b)))
+	w.Write(b)
+}
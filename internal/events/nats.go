@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// NATSPublisher publishes events as JSON on a NATS core subject, speaking
+// the plain-text NATS protocol directly over a TCP connection rather than
+// vendoring the nats.go client.
+type NATSPublisher struct {
+	Addr    string // host:port
+	Subject string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewNATSPublisher(addr, subject string) *NATSPublisher {
+	return &NATSPublisher{Addr: addr, Subject: subject}
+}
+
+func (p *NATSPublisher) connect() (net.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		return p.conn, nil
+	}
+	conn, err := net.DialTimeout("tcp", p.Addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	// The server greets with an INFO line first; a client is free to send
+	// CONNECT immediately without waiting for or parsing it.
+	if _, err := fmt.Fprintf(conn, "CONNECT {\"verbose\":false,\"pedantic\":false}\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p.conn = conn
+	return conn, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, ev Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	conn, err := p.connect()
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = fmt.Fprintf(conn, "PUB %s %d\r\n%s\r\n", p.Subject, len(payload), payload)
+	if err != nil {
+		// A write error likely means the connection died; drop it so the
+		// next Publish reconnects instead of retrying a dead socket.
+		conn.Close()
+		p.conn = nil
+	}
+	return err
+}
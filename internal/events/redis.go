@@ -0,0 +1,131 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisStreamsPublisher XADDs events onto a Redis stream, speaking RESP
+// directly over a TCP connection rather than vendoring a Redis client.
+type RedisStreamsPublisher struct {
+	Addr   string // host:port
+	Stream string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisStreamsPublisher(addr, stream string) *RedisStreamsPublisher {
+	return &RedisStreamsPublisher{Addr: addr, Stream: stream}
+}
+
+func (p *RedisStreamsPublisher) connect() error {
+	if p.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", p.Addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	return nil
+}
+
+// Publish sends XADD <stream> * field value field value ... with every
+// Event field flattened into the entry, then reads (and discards) the
+// reply so the connection stays in sync for the next call.
+func (p *RedisStreamsPublisher) Publish(ctx context.Context, ev Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.connect(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		p.conn.SetDeadline(deadline)
+	}
+
+	args := []string{"XADD", p.Stream, "*",
+		"type", string(ev.Type),
+		"timestamp", ev.Timestamp.Format(time.RFC3339Nano),
+		"backend_id", ev.BackendID,
+		"region", ev.Region,
+		"request_id", ev.RequestID,
+		"method", ev.Method,
+		"path", ev.Path,
+		"status_code", strconv.Itoa(ev.StatusCode),
+		"duration_ms", strconv.FormatFloat(float64(ev.Duration.Milliseconds()), 'f', -1, 64),
+		"reason", ev.Reason,
+	}
+
+	if _, err := p.conn.Write(encodeRESPArray(args)); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+
+	if err := p.readReply(); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+// readReply consumes one RESP reply from the connection so the next
+// Publish's read starts on a fresh reply rather than whatever's left over
+// from this one. XADD normally replies with a bulk string holding the
+// generated entry ID ($<len>\r\n<id>\r\n - two lines, not one), but on
+// error it's a single simple-error line (-ERR ...\r\n), so both shapes
+// have to be handled here rather than just discarding the first line.
+func (p *RedisStreamsPublisher) readReply() error {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '-':
+		return fmt.Errorf("redis: %s", line[1:])
+	case '+', ':':
+		return nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return fmt.Errorf("redis: malformed bulk length %q: %w", line, err)
+		}
+		if n < 0 {
+			return nil // $-1: nil bulk string, no payload line follows
+		}
+		buf := make([]byte, n+2) // payload plus trailing \r\n
+		if _, err := io.ReadFull(p.reader, buf); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
+
+// encodeRESPArray renders args as a RESP array of bulk strings, the wire
+// format every Redis command uses.
+func encodeRESPArray(args []string) []byte {
+	out := fmt.Sprintf("*%d\r\n", len(args))
+	for _, a := range args {
+		out += fmt.Sprintf("$%d\r\n%s\r\n", len(a), a)
+	}
+	return []byte(out)
+}
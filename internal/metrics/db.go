@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Instrumented wraps *sql.DB, recording db_query_duration_ms{op} on every
+// Query/QueryRow/Exec call. Every other *sql.DB method (Ping,
+// SetMaxOpenConns, ...) is promoted unchanged through the embedded field,
+// so call sites written against *sql.DB keep compiling against this type
+// without modification.
+type Instrumented struct {
+	*sql.DB
+	reg *Registry
+}
+
+func NewInstrumented(db *sql.DB, reg *Registry) *Instrumented {
+	return &Instrumented{DB: db, reg: reg}
+}
+
+func (i *Instrumented) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := i.DB.Query(query, args...)
+	i.observe("query", start)
+	return rows, err
+}
+
+func (i *Instrumented) QueryRow(query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := i.DB.QueryRow(query, args...)
+	i.observe("query_row", start)
+	return row
+}
+
+func (i *Instrumented) Exec(query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := i.DB.Exec(query, args...)
+	i.observe("exec", start)
+	return result, err
+}
+
+func (i *Instrumented) observe(op string, start time.Time) {
+	ms := float64(time.Since(start).Microseconds()) / 1000
+	i.reg.Histogram("db_query_duration_ms", `op="`+op+`"`).Observe(ms)
+}
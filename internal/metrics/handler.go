@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Handler serves the registry's current snapshot: Prometheus text
+// exposition format by default, or JSON when the request's Accept header
+// prefers it - the same negotiation a proxy's /api/info-style endpoints
+// would use if they needed to serve more than one representation.
+func Handler(reg *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			reg.WriteJSON(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		reg.WriteText(w)
+	})
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultBuckets are the exponential bucket boundaries (in milliseconds)
+// every Histogram in this package uses unless told otherwise.
+var DefaultBuckets = []float64{
+	0.5, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500,
+}
+
+// Counter is a lock-free monotonic counter: Inc/Add only ever take an
+// atomic add on the hot path.
+type Counter struct {
+	name   string
+	labels string
+	v      uint64
+}
+
+func (c *Counter) Inc()          { atomic.AddUint64(&c.v, 1) }
+func (c *Counter) Add(n uint64)  { atomic.AddUint64(&c.v, n) }
+func (c *Counter) Value() uint64 { return atomic.LoadUint64(&c.v) }
+
+// Histogram is a lock-free, fixed-bucket histogram, the same design
+// tests/fly-backend/metrics.go uses for its own request-latency buckets:
+// bucket boundaries are seeded once at construction, so Observe only needs
+// atomic.AddUint64 on the chosen bucket plus the running count/sum.
+type Histogram struct {
+	name    string
+	labels  string
+	bounds  []float64 // ascending upper bounds; last one is effectively +Inf
+	buckets []uint64
+
+	count     uint64
+	sumMicros uint64
+}
+
+// NewHistogram builds a Histogram with the given ascending bucket bounds
+// (same units the caller will pass to Observe).
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{bounds: bounds, buckets: make([]uint64, len(bounds))}
+}
+
+// Observe records a value in milliseconds.
+func (h *Histogram) Observe(ms float64) {
+	idx := sort.SearchFloat64s(h.bounds, ms)
+	if idx == len(h.bounds) {
+		idx = len(h.bounds) - 1
+	}
+	atomic.AddUint64(&h.buckets[idx], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddUint64(&h.sumMicros, uint64(ms*1000))
+}
+
+func (h *Histogram) Count() uint64 { return atomic.LoadUint64(&h.count) }
+func (h *Histogram) SumMillis() float64 {
+	return float64(atomic.LoadUint64(&h.sumMicros)) / 1000
+}
+
+// writeTo renders the histogram in Prometheus text exposition format under
+// name, with labels already formatted as `key="value",...` (or empty).
+func (h *Histogram) writeTo(w io.Writer, name, labels string) {
+	sep := ","
+	if labels == "" {
+		sep = ""
+	}
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.buckets[i])
+		le := fmt.Sprintf("%g", bound)
+		if i == len(h.bounds)-1 {
+			le = "+Inf"
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, le, cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum{%s} %g\n", name, labels, h.SumMillis())
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.Count())
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// statusRecorder captures the status code a handler wrote, since
+// http.ResponseWriter doesn't expose it after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Middleware wraps next, recording http_requests_total{route,method,status}
+// and http_request_duration_ms{route} on every call. route is supplied by
+// the caller rather than derived from the request, since contactsRouter
+// alone handles several logical routes under one registered path.
+func Middleware(reg *Registry, route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next(rec, r)
+
+		dur := time.Since(start)
+		reg.Histogram("http_request_duration_ms", fmt.Sprintf(`route=%q`, route)).
+			Observe(float64(dur.Microseconds()) / 1000)
+		reg.Counter("http_requests_total", fmt.Sprintf(`route=%q,method=%q,status=%q`, route, r.Method, strconv.Itoa(rec.status))).
+			Inc()
+	}
+}
@@ -0,0 +1,125 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ContactsGenerator produces rows for the contacts table the way
+// tests/contacts-api seeds its demo data: Brazilian/international names,
+// companies, and Portuguese notes.
+type ContactsGenerator struct{}
+
+var contactFirstNames = []string{
+	"Ana", "Pedro", "Maria", "João", "Carla", "Lucas", "Fernanda", "Rafael",
+	"Juliana", "Bruno", "Camila", "Diego", "Beatriz", "Thiago", "Amanda",
+	"Gustavo", "Larissa", "Rodrigo", "Patricia", "Leonardo", "Mariana",
+	"Felipe", "Isabela", "Daniel", "Natalia", "Eduardo", "Carolina", "Andre",
+	"Gabriela", "Ricardo", "Vanessa", "Marcos", "Leticia", "Paulo", "Renata",
+	"James", "Emma", "Michael", "Sophia", "William", "Olivia", "David", "Ava",
+	"Hans", "Greta", "Klaus", "Ingrid", "François", "Marie", "Pierre", "Claire",
+}
+
+var contactLastNames = []string{
+	"Silva", "Santos", "Oliveira", "Souza", "Lima", "Pereira", "Ferreira",
+	"Almeida", "Costa", "Rodrigues", "Martins", "Araujo", "Carvalho", "Gomes",
+	"Nascimento", "Ribeiro", "Barros", "Barbosa", "Moreira", "Melo", "Cardoso",
+	"Lopes", "Mendes", "Dias", "Ramos", "Vieira", "Nunes", "Monteiro", "Pinto",
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller",
+	"Mueller", "Schmidt", "Weber", "Dubois", "Martin", "Bernard", "Petit",
+}
+
+var contactCompanies = []string{
+	"TechCorp Brasil", "Innovate Solutions", "Digital Masters", "Cloud Nine Tech",
+	"DataFlow Systems", "Smart Logic", "ByteWise", "CodeCraft", "DevOps Pro",
+	"Agile Works", "Startup Hub", "FinTech Solutions", "E-Commerce Plus",
+	"Mobile First", "AI Dynamics", "Cyber Security SA", "Big Data Analytics",
+	"IoT Innovations", "Blockchain Labs", "SaaS Platform", "API Gateway Inc",
+	"Microservices Ltd", "Container World", "Kubernetes Masters", "AWS Partners",
+	"Google Cloud Team", "Azure Experts", "DevSecOps Group", "Terraform Co",
+	"GitLab Solutions", "GitHub Enterprise", "CI/CD Pipeline", "Monitoring Pro",
+}
+
+var contactDomains = []string{
+	"gmail.com", "outlook.com", "yahoo.com", "hotmail.com", "icloud.com",
+	"protonmail.com", "empresa.com.br", "corporativo.com", "tech.io",
+}
+
+var contactPhoneFormats = []string{
+	"+55 11 9%d%d%d%d-%d%d%d%d",
+	"+55 21 9%d%d%d%d-%d%d%d%d",
+	"+1 555 %d%d%d-%d%d%d%d",
+	"+44 20 %d%d%d%d %d%d%d%d",
+	"+49 30 %d%d%d%d%d%d%d%d",
+}
+
+var contactNotes = []string{
+	"Cliente VIP - prioridade alta",
+	"Prefere contato por email",
+	"Reunião agendada para próxima semana",
+	"Interessado em novos produtos",
+	"Parceiro estratégico",
+	"Lead qualificado",
+	"Aguardando proposta comercial",
+	"Contato referenciado por outro cliente",
+	"Participou do último evento",
+	"Potencial para upsell",
+}
+
+func (ContactsGenerator) Table() string { return "contacts" }
+
+func (ContactsGenerator) Columns() []string {
+	return []string{"name", "email", "phone", "company", "notes"}
+}
+
+func (ContactsGenerator) Schema() string {
+	return `
+		CREATE TABLE IF NOT EXISTS contacts (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			email VARCHAR(255) NOT NULL,
+			phone VARCHAR(50),
+			company VARCHAR(255),
+			notes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+}
+
+func (g ContactsGenerator) Generate(rng *rand.Rand) []interface{} {
+	firstName := contactFirstNames[rng.Intn(len(contactFirstNames))]
+	lastName := contactLastNames[rng.Intn(len(contactLastNames))]
+
+	return []interface{}{
+		firstName + " " + lastName,
+		g.randomEmail(rng, firstName, lastName),
+		g.randomPhone(rng),
+		contactCompanies[rng.Intn(len(contactCompanies))],
+		g.randomNotes(rng),
+	}
+}
+
+func (ContactsGenerator) randomPhone(rng *rand.Rand) string {
+	format := contactPhoneFormats[rng.Intn(len(contactPhoneFormats))]
+	digits := make([]interface{}, 8)
+	for i := range digits {
+		digits[i] = rng.Intn(10)
+	}
+	return fmt.Sprintf(format, digits...)
+}
+
+func (ContactsGenerator) randomEmail(rng *rand.Rand, firstName, lastName string) string {
+	domain := contactDomains[rng.Intn(len(contactDomains))]
+	formats := []string{"%s.%s@%s", "%s%s@%s", "%s_%s@%s"}
+	format := formats[rng.Intn(len(formats))]
+	return fmt.Sprintf(format, firstName, lastName, domain)
+}
+
+func (ContactsGenerator) randomNotes(rng *rand.Rand) *string {
+	if rng.Float32() > 0.5 {
+		note := contactNotes[rng.Intn(len(contactNotes))]
+		return &note
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package probe
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// AdminHandler serves the current latency matrix as JSON, for mounting at
+// /debug/latency.
+func (p *Prober) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.Snapshot())
+	})
+}
+
+// MetricsHandler serves WriteMetrics in Prometheus text exposition format,
+// for mounting at /metrics alongside a proxy's other gauges.
+func (p *Prober) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		p.WriteMetrics(w)
+	})
+}
@@ -0,0 +1,90 @@
+package probe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrebassi/edgeproxy/discovery"
+)
+
+// GeoLatencyBalancer picks the lowest-latency healthy backend for a given
+// client region, using a Prober's live RTT matrix instead of the static
+// region tag discovery.Backend carries. When the preferred region has no
+// healthy backend (an unmeasured one counts as a candidate), it falls back
+// to the lowest-latency healthy backend in any region.
+type GeoLatencyBalancer struct {
+	prober   *Prober
+	backends func() []discovery.Backend
+
+	// unmeasuredPenalty stands in as the RTT of a backend that has not been
+	// probed yet, so a measured backend faster than it beats an unknown one;
+	// a known-unhealthy backend is skipped, so an unknown one still beats it.
+	unmeasuredPenalty time.Duration
+}
+
+func NewGeoLatencyBalancer(prober *Prober, backends func() []discovery.Backend) *GeoLatencyBalancer {
+	return &GeoLatencyBalancer{
+		prober:            prober,
+		backends:          backends,
+		unmeasuredPenalty: 500 * time.Millisecond,
+	}
+}
+
+type candidate struct {
+	backend discovery.Backend
+	rtt     time.Duration
+}
+
+// Pick returns the backend with the lowest EWMA RTT as measured from
+// clientRegion. Backends in clientRegion are preferred; if none of them is
+// a candidate (healthy, and not marked unhealthy by the prober), every
+// healthy backend is considered regardless of region.
+func (b *GeoLatencyBalancer) Pick(clientRegion string) (discovery.Backend, error) {
+	all := b.backends()
+	if len(all) == 0 {
+		return discovery.Backend{}, fmt.Errorf("probe: no backends available")
+	}
+
+	if best, ok := b.best(clientRegion, all, true); ok {
+		return best.backend, nil
+	}
+	if best, ok := b.best(clientRegion, all, false); ok {
+		return best.backend, nil
+	}
+	return discovery.Backend{}, fmt.Errorf("probe: no healthy backend for region %q", clientRegion)
+}
+
+// best scans all for the lowest-RTT healthy candidate. When sameRegionOnly
+// is set, backends outside clientRegion are skipped entirely - this is the
+// fast path that keeps traffic local; the caller retries with it false to
+// fall back globally.
+func (b *GeoLatencyBalancer) best(clientRegion string, all []discovery.Backend, sameRegionOnly bool) (candidate, bool) {
+	var (
+		chosen candidate
+		found  bool
+	)
+
+	for _, backend := range all {
+		if !backend.Healthy {
+			continue
+		}
+		if sameRegionOnly && backend.Region != clientRegion {
+			continue
+		}
+
+		rtt, healthy, measured := b.prober.rtt(clientRegion, backend.ID)
+		if measured && !healthy {
+			continue
+		}
+		if !measured {
+			rtt = b.unmeasuredPenalty
+		}
+
+		if !found || rtt < chosen.rtt {
+			chosen = candidate{backend: backend, rtt: rtt}
+			found = true
+		}
+	}
+
+	return chosen, found
+}
@@ -0,0 +1,33 @@
+package probe
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteMetrics renders the current RTT matrix in Prometheus text exposition
+// format, one gauge per (client region, backend) pair - the same style
+// tests/fly-backend/metrics.go uses for its own histograms.
+func (p *Prober) WriteMetrics(w io.Writer) {
+	fmt.Fprintln(w, "# TYPE edgeproxy_probe_rtt_ewma_seconds gauge")
+	fmt.Fprintln(w, "# TYPE edgeproxy_probe_rtt_last_seconds gauge")
+	fmt.Fprintln(w, "# TYPE edgeproxy_probe_samples_total counter")
+	fmt.Fprintln(w, "# TYPE edgeproxy_probe_rejected_total counter")
+	fmt.Fprintln(w, "# TYPE edgeproxy_probe_healthy gauge")
+
+	for _, rec := range p.Snapshot() {
+		labels := fmt.Sprintf(`client_region="%s",backend_id="%s",backend_region="%s"`,
+			rec.ClientRegion, rec.BackendID, rec.BackendRegion)
+
+		fmt.Fprintf(w, "edgeproxy_probe_rtt_ewma_seconds{%s} %g\n", labels, rec.RTTEWMAMillis/1000)
+		fmt.Fprintf(w, "edgeproxy_probe_rtt_last_seconds{%s} %g\n", labels, rec.LastRTTMillis/1000)
+		fmt.Fprintf(w, "edgeproxy_probe_samples_total{%s} %d\n", labels, rec.Samples)
+		fmt.Fprintf(w, "edgeproxy_probe_rejected_total{%s} %d\n", labels, rec.Rejected)
+
+		healthy := 0
+		if rec.Healthy {
+			healthy = 1
+		}
+		fmt.Fprintf(w, "edgeproxy_probe_healthy{%s} %d\n", labels, healthy)
+	}
+}
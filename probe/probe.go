@@ -0,0 +1,258 @@
+// Package probe actively measures RTT from this edge node to every known
+// backend and feeds those measurements into GeoLatencyBalancer, so routing
+// decisions reflect live network conditions instead of a static region tag.
+package probe
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrebassi/edgeproxy/discovery"
+)
+
+// Config controls a Prober's probing cadence and EWMA smoothing.
+type Config struct {
+	// ClientRegion is this edge node's own region, the first half of every
+	// (client-region, backend) key in the matrix.
+	ClientRegion string
+
+	// Interval is the base period between probe rounds; each round is
+	// jittered by +/- Jitter to avoid every edge node hammering backends in
+	// lockstep.
+	Interval time.Duration
+	Jitter   time.Duration
+
+	// Alpha is the EWMA smoothing factor in (0, 1]; higher weighs recent
+	// samples more heavily. Defaults to 0.3.
+	Alpha float64
+
+	// RejectFactor drops a sample as an outlier if it exceeds the current
+	// EWMA by this multiple, once enough samples exist to trust the EWMA.
+	// Defaults to 4.
+	RejectFactor float64
+
+	Timeout time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.Interval == 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.Jitter == 0 {
+		c.Jitter = 2 * time.Second
+	}
+	if c.Alpha == 0 {
+		c.Alpha = 0.3
+	}
+	if c.RejectFactor == 0 {
+		c.RejectFactor = 4
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 3 * time.Second
+	}
+}
+
+type matrixKey struct {
+	ClientRegion string
+	BackendID    string
+}
+
+// entry is one cell of the latency matrix: the EWMA RTT to one backend as
+// observed from ClientRegion, plus enough bookkeeping to judge outliers and
+// staleness.
+type entry struct {
+	region   string // the backend's own region, for fallback grouping
+	ewma     time.Duration
+	samples  uint64
+	rejected uint64
+	lastRTT  time.Duration
+	lastSeen time.Time
+	healthy  bool
+}
+
+// Prober periodically hits /api/latency on every backend returned by
+// Backends and maintains an EWMA RTT matrix keyed by (client region,
+// backend). Safe for concurrent use.
+type Prober struct {
+	cfg      Config
+	backends func() []discovery.Backend
+	http     *http.Client
+
+	mu      sync.RWMutex
+	entries map[matrixKey]*entry
+}
+
+// NewProber builds a Prober that probes whatever backends returns at the
+// start of each round - typically discovery.Pool.Backends.
+func NewProber(cfg Config, backends func() []discovery.Backend) *Prober {
+	cfg.setDefaults()
+	return &Prober{
+		cfg:      cfg,
+		backends: backends,
+		http:     &http.Client{Timeout: cfg.Timeout},
+		entries:  map[matrixKey]*entry{},
+	}
+}
+
+// Run probes every backend once per interval (jittered) until ctx is
+// canceled. Meant to run in its own goroutine for the proxy's lifetime.
+func (p *Prober) Run(ctx context.Context) {
+	for {
+		jitter := time.Duration(rand.Int63n(int64(2*p.cfg.Jitter+1))) - p.cfg.Jitter
+		wait := p.cfg.Interval + jitter
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		var wg sync.WaitGroup
+		for _, b := range p.backends() {
+			b := b
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.probeOne(ctx, b)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func (p *Prober) probeOne(ctx context.Context, b discovery.Backend) {
+	reqCtx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("http://%s/api/latency", b.Address)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		p.markUnhealthy(b)
+		return
+	}
+
+	start := time.Now()
+	resp, err := p.http.Do(req)
+	rtt := time.Since(start)
+	if err != nil {
+		p.markUnhealthy(b)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		p.markUnhealthy(b)
+		return
+	}
+
+	p.update(b, rtt)
+}
+
+func (p *Prober) markUnhealthy(b discovery.Backend) {
+	key := matrixKey{ClientRegion: p.cfg.ClientRegion, BackendID: b.ID}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e := p.entries[key]
+	if e == nil {
+		e = &entry{region: b.Region}
+		p.entries[key] = e
+	}
+	e.healthy = false
+	e.lastSeen = time.Now()
+}
+
+func (p *Prober) update(b discovery.Backend, rtt time.Duration) {
+	key := matrixKey{ClientRegion: p.cfg.ClientRegion, BackendID: b.ID}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e := p.entries[key]
+	if e == nil {
+		e = &entry{region: b.Region, ewma: rtt}
+		p.entries[key] = e
+	}
+
+	// Only reject once the EWMA has had a chance to settle; otherwise the
+	// very first few samples would fight each other.
+	if e.samples >= 5 && float64(rtt) > float64(e.ewma)*p.cfg.RejectFactor {
+		e.rejected++
+		e.lastSeen = time.Now()
+		e.healthy = true
+		return
+	}
+
+	e.ewma = time.Duration(p.cfg.Alpha*float64(rtt) + (1-p.cfg.Alpha)*float64(e.ewma))
+	e.lastRTT = rtt
+	e.samples++
+	e.lastSeen = time.Now()
+	e.healthy = true
+	e.region = b.Region
+}
+
+// Record is one row of the latency matrix, as exposed by Snapshot and the
+// /debug/latency admin endpoint.
+type Record struct {
+	ClientRegion   string        `json:"client_region"`
+	BackendID      string        `json:"backend_id"`
+	BackendRegion  string        `json:"backend_region"`
+	RTTEWMAMillis  float64       `json:"rtt_ewma_ms"`
+	LastRTTMillis  float64       `json:"last_rtt_ms"`
+	Samples        uint64        `json:"samples"`
+	Rejected       uint64        `json:"rejected_outliers"`
+	Healthy        bool          `json:"healthy"`
+	LastSeenAgo    time.Duration `json:"-"`
+	LastSeenAgoSec float64       `json:"last_seen_ago_seconds"`
+}
+
+// Snapshot returns the current matrix as a stable-ordered slice, safe to
+// marshal directly to JSON.
+func (p *Prober) Snapshot() []Record {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	out := make([]Record, 0, len(p.entries))
+	now := time.Now()
+	for k, e := range p.entries {
+		ago := now.Sub(e.lastSeen)
+		out = append(out, Record{
+			ClientRegion:   k.ClientRegion,
+			BackendID:      k.BackendID,
+			BackendRegion:  e.region,
+			RTTEWMAMillis:  float64(e.ewma) / float64(time.Millisecond),
+			LastRTTMillis:  float64(e.lastRTT) / float64(time.Millisecond),
+			Samples:        e.samples,
+			Rejected:       e.rejected,
+			Healthy:        e.healthy,
+			LastSeenAgo:    ago,
+			LastSeenAgoSec: ago.Seconds(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ClientRegion != out[j].ClientRegion {
+			return out[i].ClientRegion < out[j].ClientRegion
+		}
+		return out[i].BackendID < out[j].BackendID
+	})
+	return out
+}
+
+// rtt returns the current EWMA RTT and health for backendID as seen from
+// clientRegion, or ok=false if the backend has never been probed. A failed
+// probe creates an entry too, so it reports ok=true with healthy=false.
+func (p *Prober) rtt(clientRegion, backendID string) (d time.Duration, healthy, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	e, found := p.entries[matrixKey{ClientRegion: clientRegion, BackendID: backendID}]
+	if !found {
+		return 0, false, false
+	}
+	return e.ewma, e.healthy, true
+}
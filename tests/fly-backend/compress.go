@@ -0,0 +1,78 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// encoder wraps an io.Writer so handleDownload can track the compressed
+// byte count regardless of which codec is negotiated.
+type encoder interface {
+	io.WriteCloser
+}
+
+// encoderFactories maps a Content-Encoding token to a constructor. gzip,
+// via the standard library, is the only codec implemented today; a client
+// that only accepts brotli or zstd falls through negotiateEncoding to
+// identity rather than getting a codec this binary can't actually produce.
+var encoderFactories = map[string]func(io.Writer) encoder{
+	"gzip": func(w io.Writer) encoder { return gzip.NewWriter(w) },
+}
+
+// negotiateEncoding picks the best encoding edgeProxy can actually produce
+// from the client's Accept-Encoding header, preferring earlier entries in
+// preference order. Returns "" (identity) when nothing matches.
+func negotiateEncoding(acceptEncoding string, preference []string) string {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		token := part
+		q := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			token = strings.TrimSpace(part[:i])
+			if qv := strings.TrimSpace(part[i+1:]); strings.HasPrefix(qv, "q=") {
+				if f, err := strconv.ParseFloat(qv[2:], 64); err == nil {
+					q = f
+				}
+			}
+		}
+		accepted[token] = q
+	}
+
+	for _, candidate := range preference {
+		if q, ok := accepted[candidate]; ok && q > 0 {
+			if _, registered := encoderFactories[candidate]; registered {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// fillCompressible writes data that compresses well (the byte pattern
+// 0,1,...,7 repeated) so operators can benchmark the effective
+// throughput a real CDN/edge would see with compression enabled.
+func fillCompressible(buf []byte) {
+	for i := range buf {
+		buf[i] = byte(i % 8)
+	}
+}
+
+// countingWriter tracks bytes written to an underlying writer, used to
+// measure compressed bytes actually sent on the wire.
+type countingWriter struct {
+	w http.ResponseWriter
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
@@ -0,0 +1,6 @@
+//go:build mysql
+
+package main
+
+// Built with `-tags mysql` to register the MySQL driver for DB_DRIVER=mysql.
+import _ "github.com/go-sql-driver/mysql"
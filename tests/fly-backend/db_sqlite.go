@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package main
+
+// Built with `-tags sqlite` to register the SQLite driver for DB_DRIVER=sqlite.
+import _ "github.com/mattn/go-sqlite3"
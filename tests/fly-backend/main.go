@@ -68,6 +68,7 @@ func main() {
 
 	// Initialize database if configured
 	initDB()
+	initS3()
 
 	// Basic endpoints
 	http.HandleFunc("/", handleRequest)
@@ -78,13 +79,25 @@ func main() {
 	http.HandleFunc("/api/download", handleDownload)
 	http.HandleFunc("/api/upload", handleUpload)
 	http.HandleFunc("/api/latency", handleLatency)
-	http.HandleFunc("/api/stats", handleStats)
+	http.HandleFunc("/metrics", handleMetrics)
 	http.HandleFunc("/api/info", handleInfo)
+	http.HandleFunc("/api/ws", handleWS)
+	http.HandleFunc("/ws/bench", handleWSBench)
 
 	// RDS Benchmark endpoints (v4)
 	http.HandleFunc("/api/rds/benchmark", handleRDSBenchmark)
 	http.HandleFunc("/api/rds/health", handleRDSHealth)
 
+	// S3-compatible object storage benchmark endpoints
+	http.HandleFunc("/api/s3/benchmark", handleS3Benchmark)
+	http.HandleFunc("/api/s3/health", handleS3Health)
+
+	// Historical benchmark run storage
+	http.HandleFunc("/api/runs", handleRunsList)
+	http.HandleFunc("/api/runs/summary", handleRunsSummary)
+	http.HandleFunc("/api/results", handleResults)
+	http.HandleFunc("/api/peers", handlePeers)
+
 	fmt.Printf("Backend v2 running in region [%s] on port %s\n", region, port)
 	fmt.Printf("Benchmark page: http://localhost:%s/benchmark\n", port)
 
@@ -106,25 +119,50 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func initDB() {
-	dbHost := getEnv("DB_HOST", "")
-	if dbHost == "" {
-		fmt.Println("DB_HOST not set, RDS benchmark disabled")
-		return
-	}
+// dbDriver returns the driverName passed to sql.Open, selected via
+// DB_DRIVER. Only "postgres" is registered in a default build; "mysql" and
+// "sqlite" require building with -tags mysql / -tags sqlite respectively
+// (see db_mysql.go / db_sqlite.go), mirroring how those drivers pull in
+// cgo/extra dependencies that not every deployment wants.
+func dbDriver() string {
+	return getEnv("DB_DRIVER", "postgres")
+}
 
-	dbPort := getEnv("DB_PORT", "5432")
+// dbConnString assembles the driver-specific connection string from the
+// shared DB_* env vars.
+func dbConnString(driver string) string {
 	dbUser := getEnv("DB_USER", "postgres")
 	dbPassword := getEnv("DB_PASSWORD", "")
 	dbName := getEnv("DB_NAME", "contacts")
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
+	switch driver {
+	case "mysql":
+		dbHost := getEnv("DB_HOST", "")
+		dbPort := getEnv("DB_PORT", "3306")
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", dbUser, dbPassword, dbHost, dbPort, dbName)
+	case "sqlite":
+		return getEnv("DB_SQLITE_PATH", dbName+".db")
+	default: // postgres
+		dbHost := getEnv("DB_HOST", "")
+		dbPort := getEnv("DB_PORT", "5432")
+		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			dbHost, dbPort, dbUser, dbPassword, dbName)
+	}
+}
+
+func initDB() {
+	driver := dbDriver()
+	if driver != "sqlite" && getEnv("DB_HOST", "") == "" {
+		fmt.Println("DB_HOST not set, RDS benchmark disabled")
+		return
+	}
+
+	connStr := dbConnString(driver)
 
 	var err error
-	db, err = sql.Open("postgres", connStr)
+	db, err = sql.Open(driver, connStr)
 	if err != nil {
-		fmt.Printf("Failed to open database: %v\n", err)
+		fmt.Printf("Failed to open database (driver=%s): %v\n", driver, err)
 		return
 	}
 
@@ -133,12 +171,20 @@ func initDB() {
 	db.SetConnMaxLifetime(time.Minute * 5)
 
 	if err := db.Ping(); err != nil {
-		fmt.Printf("Failed to ping database: %v\n", err)
+		fmt.Printf("Failed to ping database (driver=%s): %v\n", driver, err)
+		db = nil
+		return
+	}
+
+	if err := prepareRDSStatements(); err != nil {
+		fmt.Printf("Failed to prepare RDS benchmark statements: %v\n", err)
 		db = nil
 		return
 	}
 
-	fmt.Printf("Database connected: %s\n", dbHost)
+	initRunsTable()
+
+	fmt.Printf("Database connected (driver=%s)\n", driver)
 }
 
 func handleRDSHealth(w http.ResponseWriter, r *http.Request) {
@@ -165,91 +211,6 @@ func handleRDSHealth(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
-func handleRDSBenchmark(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Fly-Region", region)
-
-	dbHost := getEnv("DB_HOST", "not configured")
-
-	if db == nil {
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error":   "Database not configured",
-			"region":  region,
-			"db_host": dbHost,
-		})
-		return
-	}
-
-	iterations := 10
-	if iter := r.URL.Query().Get("iterations"); iter != "" {
-		if n, err := strconv.Atoi(iter); err == nil && n > 0 && n <= 100 {
-			iterations = n
-		}
-	}
-
-	readLatencies := make([]float64, iterations)
-	insertLatencies := make([]float64, iterations)
-
-	// Run READ benchmarks
-	for i := 0; i < iterations; i++ {
-		start := time.Now()
-		var count int
-		db.QueryRow("SELECT COUNT(*) FROM contacts").Scan(&count)
-		readLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
-	}
-
-	// Run INSERT benchmarks
-	for i := 0; i < iterations; i++ {
-		start := time.Now()
-		name := fmt.Sprintf("Bench-%s-%d-%d", region, time.Now().UnixNano(), i)
-		email := fmt.Sprintf("bench-%d@test.local", time.Now().UnixNano())
-		db.Exec(`INSERT INTO contacts (name, email, notes) VALUES ($1, $2, $3)`,
-			name, email, "Benchmark")
-		insertLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
-	}
-
-	// Calculate stats
-	calcStats := func(latencies []float64) (avg, min, max float64) {
-		if len(latencies) == 0 {
-			return 0, 0, 0
-		}
-		min = latencies[0]
-		max = latencies[0]
-		var sum float64
-		for _, l := range latencies {
-			sum += l
-			if l < min {
-				min = l
-			}
-			if l > max {
-				max = l
-			}
-		}
-		avg = sum / float64(len(latencies))
-		return
-	}
-
-	readAvg, readMin, readMax := calcStats(readLatencies)
-	insertAvg, insertMin, insertMax := calcStats(insertLatencies)
-
-	result := map[string]interface{}{
-		"region":           region,
-		"db_host":          dbHost,
-		"iterations":       iterations,
-		"read_avg_ms":      readAvg,
-		"read_min_ms":      readMin,
-		"read_max_ms":      readMax,
-		"insert_avg_ms":    insertAvg,
-		"insert_min_ms":    insertMin,
-		"insert_max_ms":    insertMax,
-		"read_latencies":   readLatencies,
-		"insert_latencies": insertLatencies,
-		"timestamp":        time.Now().UTC().Format(time.RFC3339),
-	}
-
-	json.NewEncoder(w).Encode(result)
-}
-
 func handleRequest(w http.ResponseWriter, r *http.Request) {
 	atomic.AddUint64(&requestCount, 1)
 
@@ -272,6 +233,7 @@ func handleRequest(w http.ResponseWriter, r *http.Request) {
 
 // handleInfo returns JSON with backend info
 func handleInfo(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	atomic.AddUint64(&requestCount, 1)
 
 	names, ok := regionNames[region]
@@ -288,28 +250,38 @@ func handleInfo(w http.ResponseWriter, r *http.Request) {
 		"bytes_served": atomic.LoadUint64(&bytesServed),
 		"timestamp":    time.Now().UTC().Format(time.RFC3339),
 	}
+	handlerDur := time.Since(start)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Fly-Region", region)
+	setServerTiming(w, serverTimingPhase{"handler", handlerDur})
 	json.NewEncoder(w).Encode(info)
+
+	observeRequest("info", start)
 }
 
 // handleLatency returns minimal response for latency testing
 func handleLatency(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	atomic.AddUint64(&requestCount, 1)
 
+	setCORSHeaders(w)
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Fly-Region", region)
 	w.Header().Set("X-Server-Time", strconv.FormatInt(time.Now().UnixNano(), 10))
+	setServerTiming(w, serverTimingPhase{"handler", time.Since(start)})
 
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"region": region,
 		"ts":     time.Now().UnixNano(),
 	})
+
+	observeRequest("latency", start)
 }
 
 // handleDownload generates random data for download speed testing
 func handleDownload(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	atomic.AddUint64(&requestCount, 1)
 
 	// Default 1MB, max 100MB
@@ -325,13 +297,36 @@ func handleDownload(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	compressible := r.URL.Query().Get("compressible") == "true"
+	codec := negotiateEncoding(r.Header.Get("Accept-Encoding"), []string{"gzip"})
+	setupDur := time.Since(start)
+
+	setCORSHeaders(w)
 	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Length", strconv.Itoa(size))
 	w.Header().Set("X-Fly-Region", region)
 	w.Header().Set("X-File-Size", strconv.Itoa(size))
+	w.Header().Set("X-Uncompressed-Size", strconv.Itoa(size))
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"test-%s-%d.bin\"", region, size))
+	// Only setup time is known before the body starts streaming; the
+	// transfer itself can't be reported here since headers precede the body.
+	setServerTiming(w, serverTimingPhase{"setup", setupDur})
+
+	var out io.Writer = w
+	counter := &countingWriter{w: w}
+	var enc encoder
+
+	if codec != "" {
+		w.Header().Set("Content-Encoding", codec)
+		w.Header().Set("Transfer-Encoding", "chunked")
+		enc = encoderFactories[codec](counter)
+		out = enc
+	} else {
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		out = counter
+	}
 
-	// Stream random data in chunks
+	// Stream data in chunks; random for raw-throughput testing, or
+	// pseudo-repeated so the compression path is actually exercised.
 	chunkSize := 64 * 1024 // 64KB chunks
 	chunk := make([]byte, chunkSize)
 	remaining := size
@@ -342,18 +337,30 @@ func handleDownload(w http.ResponseWriter, r *http.Request) {
 			toWrite = remaining
 		}
 
-		rand.Read(chunk[:toWrite])
-		n, err := w.Write(chunk[:toWrite])
+		if compressible {
+			fillCompressible(chunk[:toWrite])
+		} else {
+			rand.Read(chunk[:toWrite])
+		}
+		n, err := out.Write(chunk[:toWrite])
 		if err != nil {
 			return
 		}
 		remaining -= n
-		atomic.AddUint64(&bytesServed, uint64(n))
 	}
+
+	if enc != nil {
+		enc.Close()
+	}
+	atomic.AddUint64(&bytesServed, counter.n)
+
+	downloadBytesHist.Observe(float64(counter.n))
+	observeRequest("download", start)
 }
 
 // handleUpload receives data for upload speed testing
 func handleUpload(w http.ResponseWriter, r *http.Request) {
+	reqStart := time.Now()
 	atomic.AddUint64(&requestCount, 1)
 
 	if r.Method != "POST" {
@@ -369,6 +376,7 @@ func handleUpload(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Fly-Region", region)
+	setServerTiming(w, serverTimingPhase{"io", elapsed})
 
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"region":     region,
@@ -376,26 +384,9 @@ func handleUpload(w http.ResponseWriter, r *http.Request) {
 		"elapsed_ms": elapsed.Milliseconds(),
 		"speed_mbps": speedMbps,
 	})
-}
 
-// handleStats returns server statistics
-func handleStats(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Fly-Region", region)
-
-	uptime := time.Since(startTime)
-	reqs := atomic.LoadUint64(&requestCount)
-	bytes := atomic.LoadUint64(&bytesServed)
-
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"region":        region,
-		"uptime":        uptime.String(),
-		"uptime_secs":   int(uptime.Seconds()),
-		"requests":      reqs,
-		"bytes_served":  bytes,
-		"mb_served":     float64(bytes) / 1024 / 1024,
-		"reqs_per_sec":  float64(reqs) / uptime.Seconds(),
-	})
+	uploadMbpsHist.Observe(speedMbps)
+	observeRequest("upload", reqStart)
 }
 
 // handleBenchmarkPage serves the HTML benchmark page
@@ -686,8 +677,17 @@ const benchmarkHTML = `<!DOCTYPE html>
                     <span class="metric-label">Samples</span>
                     <span class="metric-value" id="latencySamples">0</span>
                 </div>
+                <div class="metric">
+                    <span class="metric-label">Jitter (RFC 3550)</span>
+                    <span class="metric-value" id="latencyJitter">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Packet Loss</span>
+                    <span class="metric-value" id="packetLoss">-</span>
+                </div>
                 <div class="latency-chart" id="latencyChart"></div>
                 <button onclick="startLatencyTest()" id="latencyBtn">Start Latency Test (50 pings)</button>
+                <button onclick="startPacketLossTest()" id="packetLossBtn" class="secondary">Test Packet Loss (30 pings, 2s timeout)</button>
             </div>
 
             <!-- Download Speed -->
@@ -716,6 +716,16 @@ const benchmarkHTML = `<!DOCTYPE html>
                     <option value="52428800">50 MB</option>
                     <option value="104857600">100 MB</option>
                 </select>
+                <select id="downloadConnections" style="width: 100%; padding: 10px; margin: 10px 0; background: #333; color: #fff; border: none; border-radius: 5px;">
+                    <option value="1">1 connection</option>
+                    <option value="4" selected>4 connections</option>
+                    <option value="8">8 connections</option>
+                    <option value="16">16 connections</option>
+                </select>
+                <div class="metric">
+                    <span class="metric-label">Per-connection</span>
+                    <span class="metric-value" id="downloadPerConn">-</span>
+                </div>
                 <button onclick="startDownloadTest()" id="downloadBtn">Start Download Test</button>
             </div>
 
@@ -743,6 +753,16 @@ const benchmarkHTML = `<!DOCTYPE html>
                     <option value="10485760">10 MB</option>
                     <option value="26214400">25 MB</option>
                 </select>
+                <select id="uploadConnections" style="width: 100%; padding: 10px; margin: 10px 0; background: #333; color: #fff; border: none; border-radius: 5px;">
+                    <option value="1">1 connection</option>
+                    <option value="4" selected>4 connections</option>
+                    <option value="8">8 connections</option>
+                    <option value="16">16 connections</option>
+                </select>
+                <div class="metric">
+                    <span class="metric-label">Per-connection</span>
+                    <span class="metric-value" id="uploadPerConn">-</span>
+                </div>
                 <button onclick="startUploadTest()" id="uploadBtn">Start Upload Test</button>
             </div>
 
@@ -778,6 +798,128 @@ const benchmarkHTML = `<!DOCTYPE html>
                 <button onclick="startStressTest()" id="stressBtn">Start Stress Test</button>
             </div>
 
+            <!-- Live WebSocket Test -->
+            <div class="card">
+                <h2>🔌 Live (WebSocket)</h2>
+                <div class="metric">
+                    <span class="metric-label">Status</span>
+                    <span class="metric-value" id="liveStatus">disconnected</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">RTT (last / avg)</span>
+                    <span class="metric-value" id="liveRtt">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Jitter</span>
+                    <span class="metric-value" id="liveJitter">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Down / Up</span>
+                    <span class="metric-value" id="liveThroughput">-</span>
+                </div>
+                <button onclick="startLiveTest()" id="liveBtn">Start Live Test</button>
+            </div>
+
+            <!-- WebSocket Benchmark -->
+            <div class="card">
+                <h2>WebSocket Test</h2>
+                <select id="wsBenchDuration" style="width: 100%; padding: 10px; margin: 10px 0; background: #333; color: #fff; border: none; border-radius: 5px;">
+                    <option value="2000">2 seconds</option>
+                    <option value="5000" selected>5 seconds</option>
+                    <option value="10000">10 seconds</option>
+                </select>
+                <label style="display: flex; align-items: center; gap: 8px; margin: 10px 0; color: #ccc;">
+                    <input type="checkbox" id="wsBenchDuplex">
+                    Duplex (download + upload at once)
+                </label>
+                <div class="metric">
+                    <span class="metric-label">RTT</span>
+                    <span class="metric-value" id="wsBenchRtt">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Download</span>
+                    <span class="metric-value" id="wsBenchDown">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Upload</span>
+                    <span class="metric-value" id="wsBenchUp">-</span>
+                </div>
+                <button onclick="startWSBenchTest()" id="wsBenchBtn">Start WebSocket Test</button>
+            </div>
+
+            <!-- Results History -->
+            <div class="card">
+                <h2>History</h2>
+                <div class="metric">
+                    <span class="metric-label">Latency (avg ms)</span>
+                    <span class="metric-value" id="historyLatency">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Download (Mbps)</span>
+                    <span class="metric-value" id="historyDownload">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Upload (Mbps)</span>
+                    <span class="metric-value" id="historyUpload">-</span>
+                </div>
+                <div class="metric">
+                    <span class="metric-label">Stress (req/s)</span>
+                    <span class="metric-value" id="historyStress">-</span>
+                </div>
+                <button onclick="refreshHistory()" class="secondary">Refresh</button>
+                <button onclick="exportHistory('csv')" class="secondary">Export CSV</button>
+                <button onclick="exportHistory('json')" class="secondary">Export JSON</button>
+
+                <div style="margin-top: 15px; border-top: 1px solid #333; padding-top: 15px;">
+                    <div style="color: #9ca3af; margin-bottom: 5px;">Compare runs</div>
+                    <select id="compareRun1" style="width: 100%; padding: 8px; margin: 5px 0; background: #333; color: #fff; border: none; border-radius: 5px;"></select>
+                    <select id="compareRun2" style="width: 100%; padding: 8px; margin: 5px 0; background: #333; color: #fff; border: none; border-radius: 5px;"></select>
+                    <button onclick="compareRuns()" class="secondary">Compare</button>
+                    <div id="compareResult" style="margin-top: 10px; font-size: 0.9em;"></div>
+                </div>
+            </div>
+
+            <!-- Global Map -->
+            <div class="card">
+                <h2>Global Map</h2>
+                <div id="peerBadges" style="display: flex; flex-wrap: wrap; gap: 8px; margin-bottom: 10px;"></div>
+                <table id="peerTable" style="width: 100%; border-collapse: collapse; font-size: 0.9em;">
+                    <thead>
+                        <tr style="text-align: left; color: #9ca3af;">
+                            <th style="padding: 4px;">Region</th>
+                            <th style="padding: 4px;">RTT</th>
+                            <th style="padding: 4px;">Download</th>
+                        </tr>
+                    </thead>
+                    <tbody id="peerTableBody">
+                        <tr><td colspan="3" style="padding: 4px; color: #666;">Not probed yet</td></tr>
+                    </tbody>
+                </table>
+                <button onclick="probePeers()" id="peerBtn" class="secondary" style="margin-top: 10px">Probe Peers</button>
+            </div>
+
+            <!-- Waterfall -->
+            <div class="card">
+                <h2>Waterfall</h2>
+                <table id="waterfallTable" style="width: 100%; border-collapse: collapse; font-size: 0.85em;">
+                    <thead>
+                        <tr style="text-align: left; color: #9ca3af;">
+                            <th style="padding: 4px;">Test</th>
+                            <th style="padding: 4px;">DNS</th>
+                            <th style="padding: 4px;">Connect</th>
+                            <th style="padding: 4px;">TLS</th>
+                            <th style="padding: 4px;">TTFB</th>
+                            <th style="padding: 4px;">Download</th>
+                            <th style="padding: 4px;">Server-Timing</th>
+                        </tr>
+                    </thead>
+                    <tbody id="waterfallBody">
+                        <tr><td colspan="7" style="padding: 4px; color: #666;">Not captured yet</td></tr>
+                    </tbody>
+                </table>
+                <button onclick="runWaterfallTest()" id="waterfallBtn" class="secondary" style="margin-top: 10px">Capture Waterfall</button>
+            </div>
+
             <!-- Test Log -->
             <div class="card">
                 <h2>üìã Test Log</h2>
@@ -799,6 +941,7 @@ const benchmarkHTML = `<!DOCTYPE html>
         // State
         let latencyResults = [];
         let serverRegion = 'unknown';
+        let rttJitter = 0;
 
         // Utility functions
         function formatBytes(bytes) {
@@ -813,6 +956,133 @@ const benchmarkHTML = `<!DOCTYPE html>
             return (ms / 1000).toFixed(2) + ' s';
         }
 
+        // Results history: POST every test outcome to /api/results so
+        // operators can track a node's performance over time instead of only
+        // seeing the latest run. If the server is unreachable, fall back to
+        // localStorage so the History card still has something to render.
+        const RESULTS_LOCAL_KEY = 'edgeproxy_results';
+
+        function loadLocalResults() {
+            try {
+                return JSON.parse(localStorage.getItem(RESULTS_LOCAL_KEY) || '[]');
+            } catch (e) {
+                return [];
+            }
+        }
+
+        function saveLocalResult(rec) {
+            const local = loadLocalResults();
+            local.push(rec);
+            while (local.length > 200) local.shift();
+            localStorage.setItem(RESULTS_LOCAL_KEY, JSON.stringify(local));
+        }
+
+        async function recordResult(kind, fields) {
+            const rec = Object.assign({ kind, timestamp: new Date().toISOString() }, fields);
+            try {
+                const res = await fetch('/api/results', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify(rec)
+                });
+                if (!res.ok) throw new Error('status ' + res.status);
+            } catch (e) {
+                saveLocalResult(rec);
+            }
+            refreshHistory();
+        }
+
+        // renderSparkline draws a minimal inline SVG polyline - no charting
+        // library is vendored, and a handful of points doesn't need one.
+        function renderSparkline(values) {
+            if (values.length === 0) return '<span style="color:#666">no data</span>';
+            const w = 120, h = 30;
+            const min = Math.min(...values), max = Math.max(...values);
+            const range = (max - min) || 1;
+            const points = values.map((v, i) => {
+                const x = (i / Math.max(values.length - 1, 1)) * w;
+                const y = h - ((v - min) / range) * h;
+                return x.toFixed(1) + ',' + y.toFixed(1);
+            }).join(' ');
+            return '<svg width="' + w + '" height="' + h + '" style="vertical-align:middle">' +
+                '<polyline points="' + points + '" fill="none" stroke="#4ade80" stroke-width="2"/></svg>';
+        }
+
+        async function refreshHistory() {
+            let records = [];
+            try {
+                const res = await fetch('/api/results?limit=50');
+                const data = await res.json();
+                records = data.results || [];
+            } catch (e) {
+                // fall through to local-only history below
+            }
+            records = records.concat(loadLocalResults());
+            records.sort((a, b) => new Date(a.timestamp) - new Date(b.timestamp));
+
+            window._historyRecords = records;
+
+            const byKind = (kind, field) => records.filter(r => r.kind === kind && r[field] != null).map(r => r[field]);
+
+            document.getElementById('historyLatency').innerHTML = renderSparkline(byKind('latency', 'rtt_avg_ms'));
+            document.getElementById('historyDownload').innerHTML = renderSparkline(byKind('download', 'mbps'));
+            document.getElementById('historyUpload').innerHTML = renderSparkline(byKind('upload', 'mbps'));
+            document.getElementById('historyStress').innerHTML = renderSparkline(byKind('stress', 'stress_rps'));
+
+            const select1 = document.getElementById('compareRun1');
+            const select2 = document.getElementById('compareRun2');
+            const options = records.map((r, i) =>
+                '<option value="' + i + '">' + r.timestamp + ' - ' + r.kind + '</option>').join('');
+            select1.innerHTML = options;
+            select2.innerHTML = options;
+        }
+
+        function exportHistory(format) {
+            const records = window._historyRecords || [];
+            let blob, filename;
+            if (format === 'csv') {
+                const cols = ['timestamp', 'region', 'kind', 'rtt_avg_ms', 'rtt_p95_ms', 'jitter_ms', 'loss_pct', 'mbps', 'stress_rps'];
+                const lines = [cols.join(',')].concat(
+                    records.map(r => cols.map(c => r[c] != null ? r[c] : '').join(','))
+                );
+                blob = new Blob([lines.join('\n')], { type: 'text/csv' });
+                filename = 'edgeproxy-results.csv';
+            } else {
+                blob = new Blob([JSON.stringify(records, null, 2)], { type: 'application/json' });
+                filename = 'edgeproxy-results.json';
+            }
+            const a = document.createElement('a');
+            a.href = URL.createObjectURL(blob);
+            a.download = filename;
+            a.click();
+            URL.revokeObjectURL(a.href);
+        }
+
+        // compareRuns diffs two selected runs field-by-field and flags a
+        // metric as a regression when it got more than 10% worse: lower for
+        // mbps and stress_rps, higher for the latency, jitter and loss fields.
+        function compareRuns() {
+            const records = window._historyRecords || [];
+            const i1 = parseInt(document.getElementById('compareRun1').value);
+            const i2 = parseInt(document.getElementById('compareRun2').value);
+            const out = document.getElementById('compareResult');
+            if (isNaN(i1) || isNaN(i2) || !records[i1] || !records[i2]) {
+                out.textContent = 'Select two runs to compare.';
+                return;
+            }
+
+            const a = records[i1], b = records[i2];
+            const fields = ['rtt_avg_ms', 'rtt_p95_ms', 'jitter_ms', 'loss_pct', 'mbps', 'stress_rps'];
+            const rows = fields.filter(f => a[f] != null && b[f] != null).map(f => {
+                const delta = ((b[f] - a[f]) / a[f]) * 100;
+                const worse = (f === 'mbps' || f === 'stress_rps') ? delta < -10 : delta > 10;
+                const style = worse ? 'color:#f87171' : 'color:#9ca3af';
+                return '<div style="' + style + '">' + f + ': ' + a[f].toFixed(2) + ' -> ' + b[f].toFixed(2) +
+                    ' (' + (delta >= 0 ? '+' : '') + delta.toFixed(1) + '%)' + (worse ? ' regression' : '') + '</div>';
+            });
+            out.innerHTML = rows.length ? rows.join('') : 'No overlapping metrics between these two runs.';
+        }
+
         function log(msg, type = 'info') {
             const logEl = document.getElementById('testLog');
             const entry = document.createElement('div');
@@ -881,6 +1151,14 @@ const benchmarkHTML = `<!DOCTYPE html>
                     bar.style.height = Math.min(latency, 100) + '%';
                     chart.appendChild(bar);
 
+                    // RFC 3550 jitter: J = J + (|D(i-1,i)| - J) / 16
+                    if (latencyResults.length > 1) {
+                        const prev = latencyResults[latencyResults.length - 2];
+                        const d = Math.abs(latency - prev);
+                        rttJitter = rttJitter + (d - rttJitter) / 16;
+                        document.getElementById('latencyJitter').textContent = rttJitter.toFixed(2) + ' ms';
+                    }
+
                 } catch (e) {
                     log('Ping failed: ' + e.message, 'error');
                 }
@@ -891,50 +1169,106 @@ const benchmarkHTML = `<!DOCTYPE html>
             const finalAvg = latencyResults.reduce((a, b) => a + b, 0) / latencyResults.length;
             log('Latency test complete. Avg: ' + finalAvg.toFixed(1) + ' ms', 'success');
 
+            const sorted = [...latencyResults].sort((a, b) => a - b);
+            const p95 = sorted[Math.floor(sorted.length * 0.95)] || sorted[sorted.length - 1];
+            recordResult('latency', { rtt_avg_ms: finalAvg, rtt_p95_ms: p95, jitter_ms: rttJitter });
+
             btn.disabled = false;
             btn.textContent = 'Start Latency Test (50 pings)';
         }
 
+        // Packet loss estimator: fire a burst of pings with a short timeout
+        // and count timeouts vs. successes.
+        async function startPacketLossTest() {
+            const btn = document.getElementById('packetLossBtn');
+            btn.disabled = true;
+            btn.textContent = 'Testing...';
+
+            const total = 30;
+            const timeoutMs = 2000;
+            let lost = 0;
+
+            log('Starting packet loss test (' + total + ' pings, ' + timeoutMs + 'ms timeout)...');
+
+            for (let i = 0; i < total; i++) {
+                const controller = new AbortController();
+                const timer = setTimeout(() => controller.abort(), timeoutMs);
+                try {
+                    await fetch('/api/latency?_=' + Date.now() + '_' + i, { signal: controller.signal });
+                } catch (e) {
+                    lost++;
+                } finally {
+                    clearTimeout(timer);
+                }
+            }
+
+            const lossPct = (lost / total * 100).toFixed(1);
+            document.getElementById('packetLoss').textContent = lossPct + '% (' + lost + '/' + total + ')';
+            log('Packet loss test complete: ' + lossPct + '% lost', lost > 0 ? 'warning' : 'success');
+
+            recordResult('latency', { loss_pct: parseFloat(lossPct) });
+
+            btn.disabled = false;
+            btn.textContent = 'Test Packet Loss (30 pings, 2s timeout)';
+        }
+
         // Download test
         async function startDownloadTest() {
             const btn = document.getElementById('downloadBtn');
             const size = parseInt(document.getElementById('downloadSize').value);
+            const connections = parseInt(document.getElementById('downloadConnections').value);
 
             btn.disabled = true;
             btn.textContent = 'Downloading...';
             document.getElementById('downloadProgress').style.width = '0%';
             document.getElementById('downloadSpeed').textContent = '-';
 
-            log('Starting download test (' + formatBytes(size) + ')...');
-
-            try {
-                const start = performance.now();
-                const res = await fetch('/api/download?size=' + size);
+            log('Starting download test (' + formatBytes(size) + ', ' + connections + ' connections)...');
 
-                const reader = res.body.getReader();
-                let received = 0;
-
-                while (true) {
-                    const { done, value } = await reader.read();
-                    if (done) break;
-                    received += value.length;
-
-                    const progress = (received / size * 100);
-                    document.getElementById('downloadProgress').style.width = progress + '%';
-                    document.getElementById('downloadProgress').textContent = progress.toFixed(0) + '%';
+            // Split the requested size into chunks assigned round-robin to
+            // workers, each measured independently so we can report both
+            // aggregate throughput and the per-connection breakdown.
+            const chunkSize = Math.ceil(size / connections);
+            const received = new Array(connections).fill(0);
+            const finished = new Array(connections).fill(0);
+            const start = performance.now();
 
-                    const elapsed = (performance.now() - start) / 1000;
-                    const speed = (received * 8 / elapsed / 1024 / 1024);
-                    document.getElementById('downloadSpeed').textContent = speed.toFixed(2);
-                    document.getElementById('downloadBytes').textContent = formatBytes(received);
-                    document.getElementById('downloadTime').textContent = formatDuration(elapsed * 1000);
-                }
+            try {
+                await Promise.all(Array.from({ length: connections }, async (_, i) => {
+                    const res = await fetch('/api/download?size=' + chunkSize);
+                    const reader = res.body.getReader();
+
+                    while (true) {
+                        const { done, value } = await reader.read();
+                        if (done) break;
+                        received[i] += value.length;
+
+                        const totalReceived = received.reduce((a, b) => a + b, 0);
+                        const progress = (totalReceived / size * 100);
+                        document.getElementById('downloadProgress').style.width = Math.min(progress, 100) + '%';
+                        document.getElementById('downloadProgress').textContent = Math.min(progress, 100).toFixed(0) + '%';
+
+                        const elapsed = (performance.now() - start) / 1000;
+                        const speed = (totalReceived * 8 / elapsed / 1024 / 1024);
+                        document.getElementById('downloadSpeed').textContent = speed.toFixed(2);
+                        document.getElementById('downloadBytes').textContent = formatBytes(totalReceived);
+                        document.getElementById('downloadTime').textContent = formatDuration(elapsed * 1000);
+                    }
+                    finished[i] = performance.now();
+                }));
 
                 const totalTime = performance.now() - start;
-                const finalSpeed = (received * 8 / (totalTime / 1000) / 1024 / 1024);
+                const totalReceived = received.reduce((a, b) => a + b, 0);
+                const finalSpeed = (totalReceived * 8 / (totalTime / 1000) / 1024 / 1024);
                 document.getElementById('downloadSpeed').textContent = finalSpeed.toFixed(2);
+                const perConn = received.map((b, i) =>
+                    (b * 8 / ((finished[i] - start) / 1000) / 1024 / 1024).toFixed(2));
+                document.getElementById('downloadPerConn').textContent = perConn.join(' / ') + ' Mbps';
 
-                log('Download complete: ' + formatBytes(received) + ' in ' + formatDuration(totalTime) + ' (' + finalSpeed.toFixed(2) + ' Mbps)', 'success');
+                log('Download complete: ' + formatBytes(totalReceived) + ' in ' + formatDuration(totalTime) +
+                    ' (' + finalSpeed.toFixed(2) + ' Mbps aggregate, ' + connections + ' connections)', 'success');
+
+                recordResult('download', { mbps: finalSpeed });
 
             } catch (e) {
                 log('Download failed: ' + e.message, 'error');
@@ -948,40 +1282,60 @@ const benchmarkHTML = `<!DOCTYPE html>
         async function startUploadTest() {
             const btn = document.getElementById('uploadBtn');
             const size = parseInt(document.getElementById('uploadSize').value);
+            const connections = parseInt(document.getElementById('uploadConnections').value);
 
             btn.disabled = true;
             btn.textContent = 'Uploading...';
             document.getElementById('uploadProgress').style.width = '0%';
             document.getElementById('uploadSpeed').textContent = '-';
 
-            log('Starting upload test (' + formatBytes(size) + ')...');
+            log('Starting upload test (' + formatBytes(size) + ', ' + connections + ' connections)...');
+
+            const chunkSize = Math.ceil(size / connections);
+            const sent = new Array(connections).fill(0);
+            let completedConns = 0;
 
             try {
-                // Generate random data
-                const data = new Uint8Array(size);
-                crypto.getRandomValues(data);
+                const start = performance.now();
 
-                document.getElementById('uploadProgress').style.width = '50%';
-                document.getElementById('uploadProgress').textContent = 'Uploading...';
+                await Promise.all(Array.from({ length: connections }, async (_, i) => {
+                    const data = new Uint8Array(chunkSize);
+                    crypto.getRandomValues(data);
 
-                const start = performance.now();
-                const res = await fetch('/api/upload', {
-                    method: 'POST',
-                    body: data
-                });
-                const totalTime = performance.now() - start;
+                    await fetch('/api/upload', {
+                        method: 'POST',
+                        body: data
+                    });
+
+                    sent[i] = chunkSize;
+                    completedConns++;
+
+                    const totalSent = sent.reduce((a, b) => a + b, 0);
+                    const progress = (completedConns / connections * 100);
+                    document.getElementById('uploadProgress').style.width = progress + '%';
+                    document.getElementById('uploadProgress').textContent = progress.toFixed(0) + '%';
 
-                document.getElementById('uploadProgress').style.width = '100%';
-                document.getElementById('uploadProgress').textContent = '100%';
+                    const elapsed = (performance.now() - start) / 1000;
+                    const speed = (totalSent * 8 / elapsed / 1024 / 1024);
+                    document.getElementById('uploadSpeed').textContent = speed.toFixed(2);
+                    document.getElementById('uploadBytes').textContent = formatBytes(totalSent);
+                    document.getElementById('uploadTime').textContent = formatDuration(elapsed * 1000);
+                }));
 
-                const result = await res.json();
-                const speed = (size * 8 / (totalTime / 1000) / 1024 / 1024);
+                const totalTime = performance.now() - start;
+                const totalSent = sent.reduce((a, b) => a + b, 0);
+                const finalSpeed = (totalSent * 8 / (totalTime / 1000) / 1024 / 1024);
 
-                document.getElementById('uploadSpeed').textContent = speed.toFixed(2);
-                document.getElementById('uploadBytes').textContent = formatBytes(size);
+                document.getElementById('uploadSpeed').textContent = finalSpeed.toFixed(2);
+                document.getElementById('uploadBytes').textContent = formatBytes(totalSent);
                 document.getElementById('uploadTime').textContent = formatDuration(totalTime);
+                document.getElementById('uploadPerConn').textContent =
+                    (finalSpeed / connections).toFixed(2) + ' Mbps avg x' + connections;
 
-                log('Upload complete: ' + formatBytes(size) + ' in ' + formatDuration(totalTime) + ' (' + speed.toFixed(2) + ' Mbps)', 'success');
+                log('Upload complete: ' + formatBytes(totalSent) + ' in ' + formatDuration(totalTime) +
+                    ' (' + finalSpeed.toFixed(2) + ' Mbps aggregate, ' + connections + ' connections)', 'success');
+
+                recordResult('upload', { mbps: finalSpeed });
 
             } catch (e) {
                 log('Upload failed: ' + e.message, 'error');
@@ -1040,10 +1394,328 @@ const benchmarkHTML = `<!DOCTYPE html>
             log('Stress test complete: ' + completed + '/' + count + ' successful, ' + rps.toFixed(1) + ' req/sec',
                 failed > 0 ? 'warning' : 'success');
 
+            recordResult('stress', { stress_rps: rps });
+
             btn.disabled = false;
             btn.textContent = 'Start Stress Test';
         }
 
+        // Live WebSocket test
+        function startLiveTest() {
+            const btn = document.getElementById('liveBtn');
+            const statusEl = document.getElementById('liveStatus');
+            btn.disabled = true;
+
+            const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
+            const sock = new WebSocket(proto + '//' + location.host + '/api/ws');
+            sock.binaryType = 'arraybuffer';
+
+            const rtts = [];
+            let jitter = 0;
+            let seq = 0;
+            let pingTimer = null;
+
+            sock.onopen = () => {
+                statusEl.textContent = 'connected (' + (sock.protocol || 'no region tag') + ')';
+                log('Live socket connected', 'success');
+
+                pingTimer = setInterval(() => {
+                    seq++;
+                    sock.send(JSON.stringify({ t: 'ping', seq, client_ns: Date.now() * 1e6 }));
+                }, 200);
+
+                sock.send(JSON.stringify({ t: 'down', size: 32768, duration_ms: 3000 }));
+            };
+
+            let downloadBytes = 0;
+            const downloadStart = performance.now();
+
+            sock.onmessage = (ev) => {
+                if (ev.data instanceof ArrayBuffer) {
+                    downloadBytes += ev.data.byteLength;
+                    return;
+                }
+
+                const msg = JSON.parse(ev.data);
+                if (msg.t === 'ping') {
+                    const rtt = Date.now() - (msg.client_ns / 1e6);
+                    if (rtts.length > 0) {
+                        const d = Math.abs(rtt - rtts[rtts.length - 1]);
+                        jitter += (d - jitter) / 16;
+                    }
+                    rtts.push(rtt);
+                    const avg = rtts.reduce((a, b) => a + b, 0) / rtts.length;
+                    document.getElementById('liveRtt').textContent = rtt.toFixed(1) + ' / ' + avg.toFixed(1) + ' ms';
+                    document.getElementById('liveJitter').textContent = jitter.toFixed(2) + ' ms';
+                } else if (msg.t === 'down_done') {
+                    const elapsed = (performance.now() - downloadStart) / 1000;
+                    const mbps = (downloadBytes * 8 / elapsed / 1024 / 1024);
+                    document.getElementById('liveThroughput').textContent = mbps.toFixed(2) + ' Mbps down';
+                    log('Live download done: ' + mbps.toFixed(2) + ' Mbps', 'success');
+                    sock.send(JSON.stringify({ t: 'up' }));
+
+                    const chunk = new Uint8Array(32768);
+                    crypto.getRandomValues(chunk);
+                    let sent = 0;
+                    const uploadTimer = setInterval(() => {
+                        if (sent >= 5 || sock.readyState !== WebSocket.OPEN) {
+                            clearInterval(uploadTimer);
+                            sock.send(JSON.stringify({ t: 'up_done' }));
+                            return;
+                        }
+                        sock.send(chunk);
+                        sent++;
+                    }, 100);
+                } else if (msg.t === 'up_done') {
+                    document.getElementById('liveThroughput').textContent =
+                        document.getElementById('liveThroughput').textContent + ' / done up';
+                    log('Live upload done: ' + formatBytes(msg.bytes), 'success');
+                    clearInterval(pingTimer);
+                    sock.close();
+                }
+            };
+
+            sock.onerror = () => log('Live socket error', 'error');
+            sock.onclose = () => {
+                statusEl.textContent = 'disconnected';
+                btn.disabled = false;
+                clearInterval(pingTimer);
+            };
+        }
+
+        // WebSocket benchmark: measures RTT, download, and upload over a
+        // single persistent connection using the /ws/bench protocol, instead
+        // of the many one-shot fetches startDownloadTest/startUploadTest use.
+        function startWSBenchTest() {
+            const btn = document.getElementById('wsBenchBtn');
+            const duration = parseInt(document.getElementById('wsBenchDuration').value);
+            const duplex = document.getElementById('wsBenchDuplex').checked;
+            btn.disabled = true;
+
+            document.getElementById('wsBenchRtt').textContent = '-';
+            document.getElementById('wsBenchDown').textContent = '-';
+            document.getElementById('wsBenchUp').textContent = '-';
+
+            const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
+            const sock = new WebSocket(proto + '//' + location.host + '/ws/bench');
+            sock.binaryType = 'arraybuffer';
+
+            let pending = duplex ? 2 : 1;
+            let downloadBytes = 0;
+            let downloadStart = 0;
+            let uploadTimer = null;
+            const uploadChunk = new Uint8Array(32768);
+            crypto.getRandomValues(uploadChunk);
+
+            const finishOne = () => {
+                pending--;
+                if (pending <= 0) {
+                    log('WebSocket benchmark complete' + (duplex ? ' (duplex)' : ''), 'success');
+                    sock.close();
+                    btn.disabled = false;
+                }
+            };
+
+            sock.onopen = () => {
+                log('WebSocket benchmark connected' + (duplex ? ' (duplex)' : ''), 'success');
+
+                const pingStart = performance.now();
+                sock.send(JSON.stringify({ op: 'ping', t: Date.now() }));
+                sock.addEventListener('message', function onPong(ev) {
+                    if (typeof ev.data === 'string') {
+                        const msg = JSON.parse(ev.data);
+                        if (msg.op === 'ping') {
+                            document.getElementById('wsBenchRtt').textContent =
+                                (performance.now() - pingStart).toFixed(1) + ' ms';
+                            sock.removeEventListener('message', onPong);
+                        }
+                    }
+                });
+
+                downloadStart = performance.now();
+                sock.send(JSON.stringify({ op: 'dl', duration }));
+
+                if (duplex) {
+                    let sent = 0;
+                    const uploadStart = performance.now();
+                    sock.send(JSON.stringify({ op: 'ul', duration }));
+                    uploadTimer = setInterval(() => {
+                        if (performance.now() - uploadStart >= duration || sock.readyState !== WebSocket.OPEN) {
+                            clearInterval(uploadTimer);
+                            return;
+                        }
+                        sock.send(uploadChunk);
+                        sent++;
+                    }, 20);
+                }
+            };
+
+            sock.onmessage = (ev) => {
+                if (ev.data instanceof ArrayBuffer) {
+                    downloadBytes += ev.data.byteLength;
+                    const elapsed = (performance.now() - downloadStart) / 1000;
+                    const mbps = (downloadBytes * 8 / elapsed / 1024 / 1024);
+                    document.getElementById('wsBenchDown').textContent = mbps.toFixed(2) + ' Mbps';
+                    return;
+                }
+
+                const msg = JSON.parse(ev.data);
+                if (msg.op === 'dl_done') {
+                    const elapsed = (performance.now() - downloadStart) / 1000;
+                    const mbps = (downloadBytes * 8 / elapsed / 1024 / 1024);
+                    document.getElementById('wsBenchDown').textContent = mbps.toFixed(2) + ' Mbps';
+                    log('WS download done: ' + mbps.toFixed(2) + ' Mbps', 'success');
+
+                    if (!duplex) {
+                        let sent = 0;
+                        const uploadStart = performance.now();
+                        sock.send(JSON.stringify({ op: 'ul', duration }));
+                        uploadTimer = setInterval(() => {
+                            if (performance.now() - uploadStart >= duration || sock.readyState !== WebSocket.OPEN) {
+                                clearInterval(uploadTimer);
+                                return;
+                            }
+                            sock.send(uploadChunk);
+                            sent++;
+                        }, 20);
+                    } else {
+                        finishOne();
+                    }
+                } else if (msg.op === 'ul_ack') {
+                    const mbps = (msg.bytes * 8 / (duration / 1000) / 1024 / 1024);
+                    document.getElementById('wsBenchUp').textContent = mbps.toFixed(2) + ' Mbps';
+                } else if (msg.op === 'ul_done') {
+                    const mbps = (msg.bytes * 8 / (duration / 1000) / 1024 / 1024);
+                    document.getElementById('wsBenchUp').textContent = mbps.toFixed(2) + ' Mbps';
+                    log('WS upload done: ' + formatBytes(msg.bytes), 'success');
+                    finishOne();
+                }
+            };
+
+            sock.onerror = () => log('WebSocket benchmark error', 'error');
+            sock.onclose = () => { btn.disabled = false; };
+        }
+
+        // Global Map: probe this instance plus every configured sibling
+        // region in parallel, from the browser, so the table reflects RTT
+        // from wherever the operator currently is rather than from the
+        // server's point of view.
+        async function probePeers() {
+            const btn = document.getElementById('peerBtn');
+            btn.disabled = true;
+            btn.textContent = 'Probing...';
+
+            let peerList = [];
+            try {
+                const res = await fetch('/api/peers');
+                const data = await res.json();
+                peerList = [{ region: data.region + ' (this node)', url: '' }].concat(data.peers || []);
+            } catch (e) {
+                log('Failed to load peer list: ' + e.message, 'error');
+                btn.disabled = false;
+                btn.textContent = 'Probe Peers';
+                return;
+            }
+
+            const probeSize = 256 * 1024;
+            const results = await Promise.all(peerList.map(async (p) => {
+                try {
+                    const rttStart = performance.now();
+                    await fetch(p.url + '/api/latency?_=' + Date.now());
+                    const rtt = performance.now() - rttStart;
+
+                    const dlStart = performance.now();
+                    const dlRes = await fetch(p.url + '/api/download?size=' + probeSize);
+                    await dlRes.arrayBuffer();
+                    const dlElapsed = (performance.now() - dlStart) / 1000;
+                    const mbps = (probeSize * 8 / dlElapsed / 1024 / 1024);
+
+                    return { region: p.region, rtt, mbps, ok: true };
+                } catch (e) {
+                    return { region: p.region, ok: false };
+                }
+            }));
+
+            results.sort((a, b) => (a.rtt ?? Infinity) - (b.rtt ?? Infinity));
+
+            const badgeColor = (rtt) => rtt == null ? '#666' : rtt < 50 ? '#4ade80' : rtt < 150 ? '#fbbf24' : '#f87171';
+
+            document.getElementById('peerBadges').innerHTML = results.map(r =>
+                '<span style="background:' + badgeColor(r.rtt) + '; color:#111; padding:4px 10px; border-radius:12px; font-size:0.85em;">' +
+                r.region + (r.rtt != null ? ' ' + r.rtt.toFixed(0) + 'ms' : ' offline') + '</span>'
+            ).join('');
+
+            document.getElementById('peerTableBody').innerHTML = results.map(r =>
+                '<tr><td style="padding: 4px;">' + r.region + '</td>' +
+                '<td style="padding: 4px;">' + (r.rtt != null ? r.rtt.toFixed(1) + ' ms' : '-') + '</td>' +
+                '<td style="padding: 4px;">' + (r.mbps != null ? r.mbps.toFixed(2) + ' Mbps' : '-') + '</td></tr>'
+            ).join('');
+
+            log('Probed ' + results.length + ' edge(s)', 'success');
+
+            btn.disabled = false;
+            btn.textContent = 'Probe Peers';
+        }
+
+        // Waterfall: compares browser-measured Resource Timing phases
+        // (DNS/connect/TLS/TTFB/download) against the server's own
+        // Server-Timing breakdown, so slow-RTT and slow-server can be told
+        // apart instead of blending into one latency number.
+        async function runWaterfallTest() {
+            const btn = document.getElementById('waterfallBtn');
+            btn.disabled = true;
+            btn.textContent = 'Capturing...';
+
+            performance.clearResourceTimings();
+
+            const targets = [
+                { label: 'latency', path: '/api/latency?wf=' + Date.now() },
+                { label: 'info', path: '/api/info?wf=' + Date.now() },
+                { label: 'download', path: '/api/download?size=262144&wf=' + Date.now() },
+            ];
+
+            for (const t of targets) {
+                const res = await fetch(t.path);
+                await res.arrayBuffer();
+            }
+
+            // Resource Timing entries are recorded asynchronously relative to
+            // when the response body finishes; give the browser a tick.
+            await new Promise(r => setTimeout(r, 50));
+
+            const entries = performance.getEntriesByType('resource');
+            const rows = targets.map(t => {
+                const entry = entries.find(e => e.name.includes(t.path));
+                if (!entry) {
+                    return '<tr><td style="padding: 4px;">' + t.label +
+                        '</td><td colspan="6" style="padding: 4px; color: #666;">no timing entry</td></tr>';
+                }
+
+                const dns = (entry.domainLookupEnd - entry.domainLookupStart).toFixed(1);
+                const connect = (entry.connectEnd - entry.connectStart).toFixed(1);
+                const tls = entry.secureConnectionStart > 0
+                    ? (entry.connectEnd - entry.secureConnectionStart).toFixed(1) : '-';
+                const ttfb = (entry.responseStart - entry.requestStart).toFixed(1);
+                const download = (entry.responseEnd - entry.responseStart).toFixed(1);
+                const serverTiming = (entry.serverTiming || [])
+                    .map(s => s.name + '=' + s.duration.toFixed(1) + 'ms').join(', ') || '-';
+
+                return '<tr><td style="padding: 4px;">' + t.label + '</td>' +
+                    '<td style="padding: 4px;">' + dns + '</td>' +
+                    '<td style="padding: 4px;">' + connect + '</td>' +
+                    '<td style="padding: 4px;">' + tls + '</td>' +
+                    '<td style="padding: 4px;">' + ttfb + '</td>' +
+                    '<td style="padding: 4px;">' + download + '</td>' +
+                    '<td style="padding: 4px;">' + serverTiming + '</td></tr>';
+            });
+
+            document.getElementById('waterfallBody').innerHTML = rows.join('');
+            log('Waterfall capture complete', 'success');
+
+            btn.disabled = false;
+            btn.textContent = 'Capture Waterfall';
+        }
+
         // Run all tests
         async function runAllTests() {
             log('=== Running all tests ===', 'info');
@@ -1057,6 +1729,7 @@ const benchmarkHTML = `<!DOCTYPE html>
 
         // Initialize
         refreshInfo();
+        refreshHistory();
         setInterval(() => {
             document.getElementById('clientTime').textContent = new Date().toLocaleString();
         }, 1000);
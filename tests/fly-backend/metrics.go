@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// histogram is a lock-free, fixed-bucket histogram. Bucket boundaries are
+// seeded once at construction and never change, so Observe only needs
+// atomic.AddUint64 on the chosen bucket plus the running count/sum - no
+// mutex is taken on the hot path.
+type histogram struct {
+	bounds  []float64 // ascending upper bounds (le), last one is +Inf
+	buckets []uint64  // buckets[i] counts observations <= bounds[i]
+	count   uint64
+	sum     uint64  // sum of observed values, stored as value*scale to avoid float atomics
+	scale   float64 // unit conversion applied by Observe/writeTo; 1e9 for second-denominated histograms
+}
+
+// newHistogram builds a histogram whose bounds and Observe'd values are in
+// seconds, scaling the running sum to nanoseconds so it can be accumulated
+// with atomic.AddUint64. Use newScaledHistogram directly for any other unit.
+func newHistogram(bounds []float64) *histogram {
+	return newScaledHistogram(bounds, 1e9)
+}
+
+// newScaledHistogram builds a histogram for a unit other than seconds.
+// scale is the factor Observe multiplies a value by before truncating to
+// uint64, and writeTo divides back out when reporting _sum; pick one with
+// enough headroom that the accumulated sum can't wrap uint64 over the life
+// of the process (e.g. 1e3 for byte counts or throughput rates, not 1e9,
+// since those aren't sub-second durations).
+func newScaledHistogram(bounds []float64, scale float64) *histogram {
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+		scale:   scale,
+	}
+}
+
+// Observe records a value in the histogram's unit (seconds, for one built
+// via newHistogram).
+func (h *histogram) Observe(value float64) {
+	idx := sort.SearchFloat64s(h.bounds, value)
+	if idx == len(h.bounds) {
+		idx = len(h.bounds) - 1
+	}
+	atomic.AddUint64(&h.buckets[idx], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddUint64(&h.sum, uint64(value*h.scale))
+}
+
+// writeTo renders the histogram in Prometheus text exposition format under
+// the given metric name and label set (labels must already be formatted as
+// `key="value",...` without surrounding braces, or empty).
+func (h *histogram) writeTo(w io.Writer, name, labels string) {
+	lbl := labels
+	sep := ","
+	if lbl == "" {
+		sep = ""
+	}
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.buckets[i])
+		le := fmt.Sprintf("%g", bound)
+		if i == len(h.bounds)-1 {
+			le = "+Inf"
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, lbl, sep, le, cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum{%s} %g\n", name, lbl, float64(atomic.LoadUint64(&h.sum))/h.scale)
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, lbl, atomic.LoadUint64(&h.count))
+}
+
+// Exponential bucket boundaries for request/db latency, in seconds:
+// 0.1ms .. 10s.
+var latencyBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1,
+	0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+var (
+	requestDuration = map[string]*histogram{
+		"latency":  newHistogram(latencyBuckets),
+		"download": newHistogram(latencyBuckets),
+		"upload":   newHistogram(latencyBuckets),
+		"info":     newHistogram(latencyBuckets),
+	}
+	rdsOpDuration = map[string]*histogram{
+		"read":   newHistogram(latencyBuckets),
+		"insert": newHistogram(latencyBuckets),
+	}
+	// Byte counts and throughput rates aren't seconds, so these use
+	// newScaledHistogram directly with a scale sized for their own units
+	// rather than newHistogram's 1e9 (which assumes sub-second durations
+	// and would overflow the scaled sum after ~175 observations at the
+	// 100MB cap).
+	downloadBytesHist = newScaledHistogram([]float64{
+		1 << 16, 1 << 18, 1 << 20, 1 << 22, 1 << 24, 1 << 26, 1 << 27,
+	}, 1e3)
+	uploadMbpsHist = newScaledHistogram([]float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}, 1e3)
+)
+
+func observeRequest(route string, start time.Time) {
+	if h, ok := requestDuration[route]; ok {
+		h.Observe(time.Since(start).Seconds())
+	}
+}
+
+// handleMetrics exposes request/DB latency histograms plus the uptime and DB
+// health gauges in Prometheus text exposition format, labeled per region and
+// hostname so a scraper sees percentile-grade data for every edge.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	labels := fmt.Sprintf(`region="%s",hostname="%s"`, region, hostname)
+
+	fmt.Fprintln(w, "# TYPE edgeproxy_request_duration_seconds histogram")
+	for route, h := range requestDuration {
+		h.writeTo(w, "edgeproxy_request_duration_seconds", labels+fmt.Sprintf(`,route="%s"`, route))
+	}
+
+	fmt.Fprintln(w, "# TYPE edgeproxy_download_bytes histogram")
+	downloadBytesHist.writeTo(w, "edgeproxy_download_bytes", labels)
+
+	fmt.Fprintln(w, "# TYPE edgeproxy_upload_mbps histogram")
+	uploadMbpsHist.writeTo(w, "edgeproxy_upload_mbps", labels)
+
+	fmt.Fprintln(w, "# TYPE edgeproxy_rds_op_ms histogram")
+	for op, h := range rdsOpDuration {
+		h.writeTo(w, "edgeproxy_rds_op_ms", labels+fmt.Sprintf(`,op="%s"`, op))
+	}
+
+	fmt.Fprintln(w, "# TYPE edgeproxy_uptime_seconds gauge")
+	fmt.Fprintf(w, "edgeproxy_uptime_seconds{%s} %g\n", labels, time.Since(startTime).Seconds())
+
+	fmt.Fprintln(w, "# TYPE edgeproxy_db_up gauge")
+	dbUp := 0
+	if db != nil && db.Ping() == nil {
+		dbUp = 1
+	}
+	fmt.Fprintf(w, "edgeproxy_db_up{%s} %d\n", labels, dbUp)
+}
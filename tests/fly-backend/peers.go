@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// peer describes a sibling edgeProxy region the benchmark page can probe
+// directly from the browser, turning the dashboard into a nearest-edge
+// picker instead of only reporting this instance's own numbers.
+type peer struct {
+	Region string `json:"region"`
+	URL    string `json:"url"`
+}
+
+// peers is populated once at startup from EDGEPROXY_PEERS, a comma-separated
+// list of region=url pairs, e.g.
+// EDGEPROXY_PEERS=us-east=https://edge-us-east.fly.dev,eu-west=https://edge-eu-west.fly.dev
+var peers = loadPeers()
+
+func loadPeers() []peer {
+	raw := getEnv("EDGEPROXY_PEERS", "")
+	if raw == "" {
+		return nil
+	}
+
+	var out []peer
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+		out = append(out, peer{Region: parts[0], URL: strings.TrimRight(parts[1], "/")})
+	}
+	return out
+}
+
+// setCORSHeaders allows a sibling edgeProxy's dashboard to probe this
+// instance's /api/latency and /api/download directly from the browser, since
+// the "Global Map" card runs these requests from whichever node the operator
+// currently has open.
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+}
+
+func handlePeers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"region": region,
+		"peers":  peers,
+	})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// rdsStatements holds the prepared statements used by handleRDSBenchmark so
+// the driver only parses/plans the READ and INSERT queries once at startup
+// instead of on every benchmark iteration.
+type rdsStatements struct {
+	selectCount *sql.Stmt
+	insert      *sql.Stmt
+}
+
+var rdsStmts *rdsStatements
+
+// insertPlaceholders returns the driver-specific parameter markers for a
+// 3-column INSERT, the same way dbConnString branches on driver for the
+// connection string: Postgres uses numbered $1/$2/$3, everything else
+// (MySQL, SQLite) uses positional ?.
+func insertPlaceholders(driver string) string {
+	if driver == "postgres" || driver == "" {
+		return "$1, $2, $3"
+	}
+	return "?, ?, ?"
+}
+
+func prepareRDSStatements() error {
+	selectCount, err := db.Prepare("SELECT COUNT(*) FROM contacts")
+	if err != nil {
+		return err
+	}
+	insert, err := db.Prepare(fmt.Sprintf(`INSERT INTO contacts (name, email, notes) VALUES (%s)`, insertPlaceholders(dbDriver())))
+	if err != nil {
+		selectCount.Close()
+		return err
+	}
+	rdsStmts = &rdsStatements{selectCount: selectCount, insert: insert}
+	return nil
+}
+
+func benchmarkInsertName(i int) (string, string) {
+	name := fmt.Sprintf("Bench-%s-%d-%d", region, time.Now().UnixNano(), i)
+	email := fmt.Sprintf("bench-%d@test.local", time.Now().UnixNano())
+	return name, email
+}
+
+func runRDSRead() float64 {
+	start := time.Now()
+	var count int
+	rdsStmts.selectCount.QueryRow().Scan(&count)
+	elapsed := time.Since(start)
+	rdsOpDuration["read"].Observe(elapsed.Seconds())
+	return float64(elapsed.Microseconds()) / 1000.0
+}
+
+func runRDSInsert(i int) float64 {
+	start := time.Now()
+	name, email := benchmarkInsertName(i)
+	rdsStmts.insert.Exec(name, email, "Benchmark")
+	elapsed := time.Since(start)
+	rdsOpDuration["insert"].Observe(elapsed.Seconds())
+	return float64(elapsed.Microseconds()) / 1000.0
+}
+
+// runRDSTxn wraps n inserts in a single BEGIN/COMMIT and times only the
+// COMMIT, reporting the transaction-commit latency separately from a
+// single-row insert. The sample is also recorded in the insert histogram.
+func runRDSTxn(n int) float64 {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0
+	}
+	stmt := tx.Stmt(rdsStmts.insert)
+	for i := 0; i < n; i++ {
+		name, email := benchmarkInsertName(i)
+		stmt.Exec(name, email, "Benchmark")
+	}
+
+	start := time.Now()
+	tx.Commit()
+	elapsed := time.Since(start)
+	rdsOpDuration["insert"].Observe(elapsed.Seconds())
+	return float64(elapsed.Microseconds()) / 1000.0
+}
+
+// percentileStats sorts a copy of latencies and reports avg/min/max plus
+// p50/p95/p99, interpolating between adjacent samples so small iteration
+// counts still give sensible numbers.
+func percentileStats(latencies []float64) (avg, min, max, p50, p95, p99 float64) {
+	if len(latencies) == 0 {
+		return
+	}
+
+	sorted := make([]float64, len(latencies))
+	copy(sorted, latencies)
+	sort.Float64s(sorted)
+
+	min = sorted[0]
+	max = sorted[len(sorted)-1]
+
+	var sum float64
+	for _, l := range sorted {
+		sum += l
+	}
+	avg = sum / float64(len(sorted))
+
+	percentile := func(p float64) float64 {
+		if len(sorted) == 1 {
+			return sorted[0]
+		}
+		rank := p * float64(len(sorted)-1)
+		lower := int(rank)
+		upper := lower + 1
+		if upper >= len(sorted) {
+			return sorted[len(sorted)-1]
+		}
+		frac := rank - float64(lower)
+		return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+	}
+
+	p50 = percentile(0.50)
+	p95 = percentile(0.95)
+	p99 = percentile(0.99)
+	return
+}
+
+// handleRDSBenchmark measures READ/INSERT latency against the configured
+// database using prepared statements reused across iterations, reporting
+// percentile statistics alongside the existing avg/min/max.
+//
+// ?workload=read|insert|mixed|txn selects which queries to run; txn wraps
+// batches of inserts in a single transaction to isolate commit latency from
+// single-row insert latency.
+func handleRDSBenchmark(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	dbHost := getEnv("DB_HOST", "not configured")
+
+	if db == nil || rdsStmts == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Database not configured",
+			"region":  region,
+			"db_host": dbHost,
+		})
+		return
+	}
+
+	iterations := 10
+	if iter := r.URL.Query().Get("iterations"); iter != "" {
+		if n, err := strconv.Atoi(iter); err == nil && n > 0 && n <= 100 {
+			iterations = n
+		}
+	}
+
+	workload := r.URL.Query().Get("workload")
+	switch workload {
+	case "read", "insert", "mixed", "txn":
+	default:
+		workload = "mixed"
+	}
+
+	var readLatencies, insertLatencies []float64
+
+	if workload == "read" || workload == "mixed" {
+		readLatencies = make([]float64, iterations)
+		for i := 0; i < iterations; i++ {
+			readLatencies[i] = runRDSRead()
+		}
+	}
+
+	switch workload {
+	case "insert", "mixed":
+		insertLatencies = make([]float64, iterations)
+		for i := 0; i < iterations; i++ {
+			insertLatencies[i] = runRDSInsert(i)
+		}
+	case "txn":
+		insertLatencies = make([]float64, iterations)
+		for i := 0; i < iterations; i++ {
+			insertLatencies[i] = runRDSTxn(10)
+		}
+	}
+
+	result := map[string]interface{}{
+		"region":     region,
+		"db_host":    dbHost,
+		"driver":     dbDriver(),
+		"workload":   workload,
+		"iterations": iterations,
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if readLatencies != nil {
+		avg, min, max, p50, p95, p99 := percentileStats(readLatencies)
+		result["read_avg_ms"] = avg
+		result["read_min_ms"] = min
+		result["read_max_ms"] = max
+		result["read_p50_ms"] = p50
+		result["read_p95_ms"] = p95
+		result["read_p99_ms"] = p99
+		result["read_latencies"] = readLatencies
+	}
+	if insertLatencies != nil {
+		avg, min, max, p50, p95, p99 := percentileStats(insertLatencies)
+		result["insert_avg_ms"] = avg
+		result["insert_min_ms"] = min
+		result["insert_max_ms"] = max
+		result["insert_p50_ms"] = p50
+		result["insert_p95_ms"] = p95
+		result["insert_p99_ms"] = p99
+		result["insert_latencies"] = insertLatencies
+	}
+
+	json.NewEncoder(w).Encode(result)
+
+	persistBenchmarkRun("rds", map[string]interface{}{
+		"iterations": iterations,
+		"workload":   workload,
+	}, result)
+}
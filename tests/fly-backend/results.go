@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// clientResult is a single browser-side test outcome reported by the
+// benchmark page's History card. Unlike benchmark_runs (server-measured
+// RDS/S3 iterations persisted in runs.go), these records describe what the
+// client observed end-to-end, so fields are optional depending on which test
+// produced them.
+type clientResult struct {
+	ID        int64   `json:"id"`
+	Timestamp string  `json:"timestamp"`
+	Region    string  `json:"region"`
+	Kind      string  `json:"kind"` // latency | download | upload | stress
+	RTTAvgMs  float64 `json:"rtt_avg_ms,omitempty"`
+	RTTP95Ms  float64 `json:"rtt_p95_ms,omitempty"`
+	JitterMs  float64 `json:"jitter_ms,omitempty"`
+	LossPct   float64 `json:"loss_pct,omitempty"`
+	Mbps      float64 `json:"mbps,omitempty"`
+	StressRPS float64 `json:"stress_rps,omitempty"`
+}
+
+// resultsStore is a bounded in-memory ring buffer of recent client results,
+// optionally mirrored to an append-only JSON-lines file so history survives
+// a restart. A real embedded database (sqlite/bbolt) would need a build-tag
+// gated dependency the way db_mysql.go/db_sqlite.go do for SQL drivers; until
+// one is vendored, the log file is the durable copy and this buffer is what
+// serves reads.
+type resultsStore struct {
+	mu      sync.Mutex
+	nextID  int64
+	records []clientResult
+	logFile *os.File
+}
+
+const resultsMaxRecords = 500
+
+var results = newResultsStore()
+
+func newResultsStore() *resultsStore {
+	s := &resultsStore{}
+
+	path := getEnv("RESULTS_LOG_PATH", "")
+	if path == "" {
+		return s
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return s
+	}
+	s.logFile = f
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec clientResult
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+			continue
+		}
+		s.append(rec)
+	}
+	return s
+}
+
+// append adds rec to the in-memory buffer, evicting the oldest record once
+// resultsMaxRecords is exceeded. Callers must hold s.mu.
+func (s *resultsStore) append(rec clientResult) {
+	if rec.ID > s.nextID {
+		s.nextID = rec.ID
+	}
+	s.records = append(s.records, rec)
+	if len(s.records) > resultsMaxRecords {
+		s.records = s.records[len(s.records)-resultsMaxRecords:]
+	}
+}
+
+func (s *resultsStore) add(rec clientResult) clientResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	rec.ID = s.nextID
+	if rec.Timestamp == "" {
+		rec.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	s.append(rec)
+
+	if s.logFile != nil {
+		if line, err := json.Marshal(rec); err == nil {
+			s.logFile.Write(append(line, '\n'))
+		}
+	}
+	return rec
+}
+
+func (s *resultsStore) list(kind string, limit int) []clientResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]clientResult, 0, len(s.records))
+	for _, rec := range s.records {
+		if kind != "" && rec.Kind != kind {
+			continue
+		}
+		out = append(out, rec)
+	}
+	if len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+// handleResults serves POST to record a client-reported result and GET to
+// list recent ones, mirroring the single-handler-per-resource style used by
+// contactsRouter rather than registering two separate paths.
+func handleResults(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	switch r.Method {
+	case http.MethodPost:
+		var rec clientResult
+		if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid body"})
+			return
+		}
+		if rec.Region == "" {
+			rec.Region = region
+		}
+		json.NewEncoder(w).Encode(results.add(rec))
+
+	case http.MethodGet:
+		limit := 50
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil && n > 0 && n <= resultsMaxRecords {
+				limit = n
+			}
+		}
+		kind := r.URL.Query().Get("kind")
+		records := results.list(kind, limit)
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": records, "count": len(records)})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// initRunsTable lazily creates the benchmark_runs table so every call to a
+// benchmark handler has somewhere to persist its result. It only runs
+// against a writable Postgres database - benchmarking other drivers simply
+// skips history for now.
+func initRunsTable() {
+	if db == nil || dbDriver() != "postgres" {
+		return
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS benchmark_runs (
+			id SERIAL PRIMARY KEY,
+			region VARCHAR(16) NOT NULL,
+			hostname VARCHAR(64) NOT NULL,
+			kind VARCHAR(32) NOT NULL,
+			params_json JSONB NOT NULL,
+			result_json JSONB NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_benchmark_runs_kind_region ON benchmark_runs(kind, region);
+		CREATE INDEX IF NOT EXISTS idx_benchmark_runs_created_at ON benchmark_runs(created_at);
+	`)
+	if err != nil {
+		fmt.Printf("Failed to create benchmark_runs table: %v\n", err)
+	}
+}
+
+// persistBenchmarkRun stores a benchmark result for later longitudinal
+// comparison. Failures are logged and otherwise ignored - a benchmark
+// handler should never fail its response because history-writing failed.
+func persistBenchmarkRun(kind string, params, result interface{}) {
+	if db == nil || dbDriver() != "postgres" {
+		return
+	}
+
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO benchmark_runs (region, hostname, kind, params_json, result_json)
+		VALUES ($1, $2, $3, $4, $5)
+	`, region, hostname, kind, paramsJSON, resultJSON)
+	if err != nil {
+		fmt.Printf("Failed to persist benchmark run (kind=%s): %v\n", kind, err)
+	}
+}
+
+// handleRunsList returns recent benchmark runs, optionally filtered by
+// kind/region/since, most recent first.
+func handleRunsList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	if db == nil || dbDriver() != "postgres" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": "history not available", "runs": []interface{}{}})
+		return
+	}
+
+	query := `SELECT id, region, hostname, kind, params_json, result_json, created_at FROM benchmark_runs WHERE 1=1`
+	args := []interface{}{}
+
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		args = append(args, kind)
+		query += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+	if runRegion := r.URL.Query().Get("region"); runRegion != "" {
+		args = append(args, runRegion)
+		query += fmt.Sprintf(" AND region = $%d", len(args))
+	}
+	if since := r.URL.Query().Get("since"); since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			args = append(args, t)
+			query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+		}
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+	query += " ORDER BY created_at DESC LIMIT " + strconv.Itoa(limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	type runRecord struct {
+		ID        int             `json:"id"`
+		Region    string          `json:"region"`
+		Hostname  string          `json:"hostname"`
+		Kind      string          `json:"kind"`
+		Params    json.RawMessage `json:"params"`
+		Result    json.RawMessage `json:"result"`
+		CreatedAt time.Time       `json:"created_at"`
+	}
+
+	runs := []runRecord{}
+	for rows.Next() {
+		var rec runRecord
+		if err := rows.Scan(&rec.ID, &rec.Region, &rec.Hostname, &rec.Kind, &rec.Params, &rec.Result, &rec.CreatedAt); err != nil {
+			continue
+		}
+		runs = append(runs, rec)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"runs": runs, "count": len(runs)})
+}
+
+// handleRunsSummary averages the read p50/p95 latency of each region's runs
+// within the given window (insert latency is not aggregated), letting operators spot regressions
+// after a region failover or DB migration without combing through raw runs.
+func handleRunsSummary(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	if db == nil || dbDriver() != "postgres" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": "history not available"})
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	if kind == "" {
+		kind = "rds"
+	}
+	window := 24 * time.Hour
+	if w := r.URL.Query().Get("window"); w != "" {
+		if d, err := time.ParseDuration(w); err == nil {
+			window = d
+		}
+	}
+	since := time.Now().Add(-window)
+
+	rows, err := db.Query(`
+		SELECT region, result_json FROM benchmark_runs
+		WHERE kind = $1 AND created_at >= $2
+	`, kind, since)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	type accum struct {
+		p50s, p95s []float64
+	}
+	byRegion := map[string]*accum{}
+
+	for rows.Next() {
+		var runRegion string
+		var resultJSON []byte
+		if err := rows.Scan(&runRegion, &resultJSON); err != nil {
+			continue
+		}
+		var result map[string]interface{}
+		if json.Unmarshal(resultJSON, &result) != nil {
+			continue
+		}
+
+		a, ok := byRegion[runRegion]
+		if !ok {
+			a = &accum{}
+			byRegion[runRegion] = a
+		}
+		if p50, ok := result["read_p50_ms"].(float64); ok {
+			a.p50s = append(a.p50s, p50)
+		}
+		if p95, ok := result["read_p95_ms"].(float64); ok {
+			a.p95s = append(a.p95s, p95)
+		}
+	}
+
+	summary := map[string]interface{}{}
+	for runRegion, a := range byRegion {
+		summary[runRegion] = map[string]interface{}{
+			"runs":        len(a.p50s),
+			"read_p50_ms": mean(a.p50s),
+			"read_p95_ms": mean(a.p95s),
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind":      kind,
+		"window":    window.String(),
+		"by_region": summary,
+	})
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3Config holds the S3-compatible endpoint this instance benchmarks
+// against. It covers AWS S3 as well as any S3-compatible store (Backblaze
+// B2, MinIO, Tigris) that speaks the same SigV4-signed REST API.
+type s3Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Prefix    string
+}
+
+var (
+	s3Cfg       *s3Config
+	s3Status    = "disabled"
+	s3StatusErr error // last probe error, nil when healthy
+)
+
+// loadS3Config reads S3_* env vars and validates the endpoint URL: it must
+// be http/https with no embedded credentials, query, or fragment, since
+// those would silently override the signed request we build below.
+func loadS3Config() (*s3Config, error) {
+	endpoint := getEnv("S3_ENDPOINT", "")
+	if endpoint == "" {
+		return nil, nil // S3 benchmark disabled, not an error
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("S3_ENDPOINT must be http or https")
+	}
+	if u.User != nil {
+		return nil, errors.New("S3_ENDPOINT must not contain user info")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, errors.New("S3_ENDPOINT must not contain a query or fragment")
+	}
+
+	cfg := &s3Config{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    getEnv("S3_REGION", "us-east-1"),
+		AccessKey: getEnv("S3_ACCESS_KEY", ""),
+		SecretKey: getEnv("S3_SECRET_KEY", ""),
+		Bucket:    getEnv("S3_BUCKET", ""),
+		Prefix:    getEnv("S3_PREFIX", "edgeproxy-bench/"),
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("S3_BUCKET is required when S3_ENDPOINT is set")
+	}
+	return cfg, nil
+}
+
+// initS3 validates configuration and probes the bucket with a HEAD request
+// so /api/s3/health has something to report without waiting on the first
+// benchmark run.
+func initS3() {
+	cfg, err := loadS3Config()
+	if err != nil {
+		fmt.Printf("S3 benchmark disabled: %v\n", err)
+		s3Status = "error"
+		s3StatusErr = err
+		return
+	}
+	if cfg == nil {
+		return
+	}
+	s3Cfg = cfg
+
+	if _, err := s3Request("HEAD", "/"+cfg.Bucket, nil); err != nil {
+		s3Status = "error"
+		s3StatusErr = err
+		fmt.Printf("S3 bucket probe failed: %v\n", err)
+		return
+	}
+	s3Status = "connected"
+	fmt.Printf("S3 connected: %s (bucket=%s)\n", cfg.Endpoint, cfg.Bucket)
+}
+
+func handleS3Health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	result := map[string]interface{}{
+		"region": region,
+		"status": s3Status,
+	}
+	if s3Cfg != nil {
+		result["endpoint"] = s3Cfg.Endpoint
+		result["bucket"] = s3Cfg.Bucket
+		result["s3_region"] = s3Cfg.Region
+	}
+	if s3StatusErr != nil {
+		result["message"] = s3StatusErr.Error()
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func randomObjectKey() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return s3Cfg.Prefix + hex.EncodeToString(buf)
+}
+
+func randomPayload(size int) []byte {
+	buf := make([]byte, size)
+	rand.Read(buf)
+	return buf
+}
+
+// handleS3Benchmark PUTs N random objects, GETs each back verifying the
+// body length, then DELETEs them, mirroring the JSON shape of
+// handleRDSBenchmark so the same dashboard can answer "which edge is
+// closest to my object store" alongside the RDS question.
+func handleS3Benchmark(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fly-Region", region)
+
+	if s3Cfg == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  "S3 not configured",
+			"region": region,
+		})
+		return
+	}
+
+	iterations := 10
+	if iter := r.URL.Query().Get("iterations"); iter != "" {
+		if n, err := strconv.Atoi(iter); err == nil && n > 0 && n <= 100 {
+			iterations = n
+		}
+	}
+	size := 64 * 1024
+	if s := r.URL.Query().Get("size"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 10*1024*1024 {
+			size = n
+		}
+	}
+
+	keys := make([]string, iterations)
+	putLatencies := make([]float64, iterations)
+	getLatencies := make([]float64, iterations)
+	deleteLatencies := make([]float64, iterations)
+	var failures []string
+
+	for i := 0; i < iterations; i++ {
+		keys[i] = randomObjectKey()
+		payload := randomPayload(size)
+
+		start := time.Now()
+		if _, err := s3Request("PUT", "/"+s3Cfg.Bucket+"/"+keys[i], payload); err != nil {
+			failures = append(failures, fmt.Sprintf("put %s: %v", keys[i], err))
+		}
+		putLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
+	}
+
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		body, err := s3Request("GET", "/"+s3Cfg.Bucket+"/"+keys[i], nil)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("get %s: %v", keys[i], err))
+		} else if len(body) != size {
+			failures = append(failures, fmt.Sprintf("get %s: expected %d bytes, got %d", keys[i], size, len(body)))
+		}
+		getLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
+	}
+
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		if _, err := s3Request("DELETE", "/"+s3Cfg.Bucket+"/"+keys[i], nil); err != nil {
+			failures = append(failures, fmt.Sprintf("delete %s: %v", keys[i], err))
+		}
+		deleteLatencies[i] = float64(time.Since(start).Microseconds()) / 1000.0
+	}
+
+	opStats := func(latencies []float64) map[string]interface{} {
+		avg, min, max, p50, p95, p99 := percentileStats(latencies)
+		return map[string]interface{}{
+			"avg_ms": avg, "min_ms": min, "max_ms": max,
+			"p50_ms": p50, "p95_ms": p95, "p99_ms": p99,
+		}
+	}
+
+	result := map[string]interface{}{
+		"region":     region,
+		"s3_region":  s3Cfg.Region,
+		"endpoint":   s3Cfg.Endpoint,
+		"bucket":     s3Cfg.Bucket,
+		"iterations": iterations,
+		"size_bytes": size,
+		"put":        opStats(putLatencies),
+		"get":        opStats(getLatencies),
+		"delete":     opStats(deleteLatencies),
+		"failures":   failures,
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+	}
+	json.NewEncoder(w).Encode(result)
+
+	persistBenchmarkRun("s3", map[string]interface{}{
+		"iterations": iterations,
+		"size_bytes": size,
+	}, result)
+}
+
+// s3Request signs and executes a single S3 REST call with AWS SigV4, and
+// returns the response body for callers that need it (GET).
+func s3Request(method, path string, body []byte) ([]byte, error) {
+	u, err := url.Parse(s3Cfg.Endpoint + path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Host", u.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.ContentLength = int64(len(body))
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		u.Host, payloadHash, amzDate)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		"",
+		canonicalHeaders,
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, s3Cfg.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := s3SigningKey(dateStamp)
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Cfg.AccessKey, credentialScope, strings.Join(signedHeaders, ";"), signature))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode != 404 {
+		return nil, fmt.Errorf("s3 %s %s: %s", method, path, resp.Status)
+	}
+	return respBody, nil
+}
+
+func s3SigningKey(dateStamp string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+s3Cfg.SecretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, s3Cfg.Region)
+	kService := hmacSHA256(kRegion, "s3")
+	return hmacSHA256(kService, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
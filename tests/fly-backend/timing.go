@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// serverTimingPhase is one named phase reported in a Server-Timing header.
+type serverTimingPhase struct {
+	name string
+	dur  time.Duration
+}
+
+// setServerTiming writes a W3C Server-Timing header breaking a handler's
+// work into named phases, so the client can separate server processing time
+// from pure network RTT instead of the single conflated "latency" number
+// fetch() alone can measure. Must be called before the first write to w,
+// since Server-Timing is an ordinary header here, not a trailer.
+func setServerTiming(w http.ResponseWriter, phases ...serverTimingPhase) {
+	parts := make([]string, len(phases))
+	for i, p := range phases {
+		parts[i] = fmt.Sprintf("%s;dur=%.2f", p.name, float64(p.dur.Microseconds())/1000.0)
+	}
+	w.Header().Set("Server-Timing", strings.Join(parts, ", "))
+}
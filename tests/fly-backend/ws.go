@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Minimal RFC 6455 server implementation. The benchmark protocol only needs
+// text and binary frames with no extensions, so we avoid pulling in a
+// websocket dependency for this.
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+type wsOpcode byte
+
+const (
+	wsOpText   wsOpcode = 0x1
+	wsOpBinary wsOpcode = 0x2
+	wsOpClose  wsOpcode = 0x8
+	wsOpPing   wsOpcode = 0x9
+	wsOpPong   wsOpcode = 0xA
+)
+
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func wsAccept(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil, errors.New("edgeproxy: not a websocket upgrade request")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("edgeproxy: missing Sec-WebSocket-Key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("edgeproxy: response writer does not support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	// The sub-protocol carries the region so the benchmark page can label
+	// results per edge without an extra round trip.
+	header := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAcceptKey(key) + "\r\n" +
+		"Sec-WebSocket-Protocol: region." + region + "\r\n" +
+		"\r\n"
+
+	if _, err := rw.WriteString(header); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &wsConn{conn: conn, rw: rw}, nil
+}
+
+func wsAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	h.Write([]byte(wsGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
+
+// readFrame reads a single client->server frame and unmasks its payload.
+// Fragmented messages are not supported; the benchmark protocol never sends
+// them.
+func (c *wsConn) readFrame() (wsOpcode, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.rw, header); err != nil {
+		return 0, nil, err
+	}
+	opcode := wsOpcode(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.rw, ext); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.rw, ext); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	var maskKey [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.rw, maskKey[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+
+	return opcode, payload, nil
+}
+
+// writeFrame writes a single unmasked server->client frame.
+func (c *wsConn) writeFrame(opcode wsOpcode, payload []byte) error {
+	var header []byte
+	length := len(payload)
+
+	switch {
+	case length <= 125:
+		header = []byte{0x80 | byte(opcode), byte(length)}
+	case length <= 0xffff:
+		header = make([]byte, 4)
+		header[0] = 0x80 | byte(opcode)
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = make([]byte, 10)
+		header[0] = 0x80 | byte(opcode)
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+func (c *wsConn) writeText(v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(wsOpText, payload)
+}
+
+// wsFrame is the small JSON protocol spoken over /api/ws.
+type wsFrame struct {
+	T          string `json:"t"`
+	Seq        int64  `json:"seq,omitempty"`
+	ClientNs   int64  `json:"client_ns,omitempty"`
+	ServerNs   int64  `json:"server_ns,omitempty"`
+	Size       int    `json:"size,omitempty"`
+	DurationMs int    `json:"duration_ms,omitempty"`
+	Bytes      uint64 `json:"bytes,omitempty"`
+	ElapsedMs  int64  `json:"elapsed_ms,omitempty"`
+}
+
+const wsDownChunkSize = 32 * 1024
+
+// handleWS upgrades to a WebSocket and serves the ping/down/up benchmark
+// protocol over a single long-lived connection, avoiding the per-request
+// handshake overhead that handleLatency/handleDownload pay on every call.
+func handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsAccept(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer conn.Close()
+
+	for {
+		opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case wsOpClose:
+			conn.writeFrame(wsOpClose, nil)
+			return
+		case wsOpPing:
+			conn.writeFrame(wsOpPong, payload)
+			continue
+		case wsOpText:
+			// handled below
+		default:
+			continue
+		}
+
+		var frame wsFrame
+		if err := json.Unmarshal(payload, &frame); err != nil {
+			continue
+		}
+
+		switch frame.T {
+		case "ping":
+			frame.ServerNs = time.Now().UnixNano()
+			if err := conn.writeText(frame); err != nil {
+				return
+			}
+		case "down":
+			if err := wsServeDownload(conn, frame.DurationMs); err != nil {
+				return
+			}
+		case "up":
+			if err := wsServeUpload(conn); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsServeDownload blasts random binary chunks for the requested duration,
+// tracking bytesServed the same way handleDownload does.
+func wsServeDownload(conn *wsConn, durationMs int) error {
+	if durationMs <= 0 {
+		durationMs = 1000
+	}
+	deadline := time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	chunk := make([]byte, wsDownChunkSize)
+
+	var sent uint64
+	for time.Now().Before(deadline) {
+		rand.Read(chunk)
+		if err := conn.writeFrame(wsOpBinary, chunk); err != nil {
+			return err
+		}
+		sent += uint64(len(chunk))
+		atomic.AddUint64(&bytesServed, uint64(len(chunk)))
+	}
+
+	return conn.writeText(wsFrame{T: "down_done", Bytes: sent})
+}
+
+// wsServeUpload puts the connection in upload-sink mode: it drains binary
+// frames from the client and reports progress every ~250ms until the client
+// sends an up_done text frame. Any other text frame, such as a ping, is
+// ignored and not answered while in this mode.
+func wsServeUpload(conn *wsConn) error {
+	start := time.Now()
+	var received uint64
+	lastReport := start
+
+	for {
+		opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return err
+		}
+
+		switch opcode {
+		case wsOpBinary:
+			received += uint64(len(payload))
+			if time.Since(lastReport) >= 250*time.Millisecond {
+				lastReport = time.Now()
+				if err := conn.writeText(wsFrame{
+					T:         "up_progress",
+					Bytes:     received,
+					ElapsedMs: time.Since(start).Milliseconds(),
+				}); err != nil {
+					return err
+				}
+			}
+		case wsOpText:
+			var frame wsFrame
+			if json.Unmarshal(payload, &frame) == nil && frame.T == "up_done" {
+				return conn.writeText(wsFrame{
+					T:         "up_done",
+					Bytes:     received,
+					ElapsedMs: time.Since(start).Milliseconds(),
+				})
+			}
+		case wsOpClose:
+			conn.writeFrame(wsOpClose, nil)
+			return io.EOF
+		}
+	}
+}
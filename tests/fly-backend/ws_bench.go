@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// wsBenchFrame is the JSON control protocol spoken over /ws/bench. Unlike
+// /api/ws's ping/down/up probe, dl and ul here run in their own goroutines so
+// a client can issue both on one connection and measure full-duplex
+// throughput instead of one direction at a time.
+type wsBenchFrame struct {
+	Op       string `json:"op"`
+	T        int64  `json:"t,omitempty"`
+	Bytes    uint64 `json:"bytes,omitempty"`
+	Duration int    `json:"duration,omitempty"`
+}
+
+const wsBenchChunkSize = 32 * 1024
+
+// handleWSBench upgrades to a WebSocket and serves the ping/dl/ul protocol
+// described in the benchmark page. dl and ul are dispatched to their own
+// goroutine so a client running both at once gets a true duplex measurement;
+// writes are serialized through writeMu since the dl goroutine, the ul
+// goroutine, and the control loop's pong/echo replies can all write at once.
+func handleWSBench(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsAccept(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	writeFrame := func(opcode wsOpcode, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.writeFrame(opcode, payload)
+	}
+	writeJSON := func(v interface{}) error {
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return writeFrame(wsOpText, payload)
+	}
+
+	var uploadBytes uint64 // accumulated by the read loop while a ul is in flight
+
+	for {
+		opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case wsOpClose:
+			writeFrame(wsOpClose, nil)
+			return
+		case wsOpPing:
+			writeFrame(wsOpPong, payload)
+			continue
+		case wsOpBinary:
+			atomic.AddUint64(&uploadBytes, uint64(len(payload)))
+			continue
+		case wsOpText:
+			// handled below
+		default:
+			continue
+		}
+
+		var frame wsBenchFrame
+		if json.Unmarshal(payload, &frame) != nil {
+			continue
+		}
+
+		switch frame.Op {
+		case "ping":
+			// Echoed back verbatim so the client can match t against its own
+			// send time for RTT, same as the original payload it sent.
+			if writeFrame(wsOpText, payload) != nil {
+				return
+			}
+		case "dl":
+			go wsBenchServeDownload(writeFrame, writeJSON, frame.Duration)
+		case "ul":
+			atomic.StoreUint64(&uploadBytes, 0)
+			go wsBenchServeUpload(&uploadBytes, writeJSON, frame.Duration)
+		}
+	}
+}
+
+// wsBenchServeDownload blasts random binary chunks for the requested
+// duration at whatever rate the socket will take, tracking bytesServed the
+// same way handleDownload and wsServeDownload do.
+func wsBenchServeDownload(writeFrame func(wsOpcode, []byte) error, writeJSON func(interface{}) error, durationMs int) {
+	if durationMs <= 0 {
+		durationMs = 1000
+	}
+	deadline := time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	chunk := make([]byte, wsBenchChunkSize)
+
+	var sent uint64
+	for time.Now().Before(deadline) {
+		rand.Read(chunk)
+		if writeFrame(wsOpBinary, chunk) != nil {
+			return
+		}
+		sent += uint64(len(chunk))
+		atomic.AddUint64(&bytesServed, uint64(len(chunk)))
+	}
+	writeJSON(wsBenchFrame{Op: "dl_done", Bytes: sent, T: time.Now().UnixNano()})
+}
+
+// wsBenchServeUpload reports accumulated upload bytes every 250ms for the
+// requested duration, letting the client chart a live Mbps curve instead of
+// only learning the total at the end.
+func wsBenchServeUpload(received *uint64, writeJSON func(interface{}) error, durationMs int) {
+	if durationMs <= 0 {
+		durationMs = 1000
+	}
+	deadline := time.Now().Add(time.Duration(durationMs) * time.Millisecond)
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for time.Now().Before(deadline) {
+		<-ticker.C
+		if writeJSON(wsBenchFrame{Op: "ul_ack", Bytes: atomic.LoadUint64(received), T: time.Now().UnixNano()}) != nil {
+			return
+		}
+	}
+	writeJSON(wsBenchFrame{Op: "ul_done", Bytes: atomic.LoadUint64(received), T: time.Now().UnixNano()})
+}
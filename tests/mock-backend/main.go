@@ -11,14 +11,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/andrebassi/edgeproxy/discovery"
+	"github.com/andrebassi/edgeproxy/internal/events"
 )
 
 var (
@@ -28,7 +34,12 @@ var (
 	hostname  string
 
 	requestCount uint64
+	requestSeq   uint64
 	startTime    time.Time
+
+	healthy int32 = 1 // atomic bool; toggled via PUT /admin/health for drain testing
+
+	bus = events.NewBus()
 )
 
 type Response struct {
@@ -46,32 +57,181 @@ func main() {
 	flag.StringVar(&port, "port", "9001", "Port to listen on")
 	flag.StringVar(&region, "region", "eu", "Region identifier (eu, us, sa, ap)")
 	flag.StringVar(&backendID, "id", "", "Backend ID (default: mock-{region}-{port})")
+	discoveryBackend := flag.String("discovery", "none", "Self-registration backend: consul, etcd, or none (k8s is accepted, but its registry rejects Register)")
+	discoveryAddr := flag.String("discovery-addr", "", "Registry address (e.g. http://127.0.0.1:8500 for consul)")
+	discoveryService := flag.String("discovery-service", "edgeproxy-backends", "Service/prefix name to register under")
+	advertiseAddr := flag.String("advertise-addr", "", "host:port this backend is reachable at (default: localhost:port)")
+	weight := flag.Int("weight", 1, "Load-balancer weight advertised to the registry")
+	eventsSink := flag.String("events-sink", "none", "External event sink: nats, kafka, redis, or none")
+	eventsAddr := flag.String("events-addr", "", "Event sink address (host:port)")
+	eventsTarget := flag.String("events-target", "edgeproxy.events", "Subject/topic/stream name for the event sink")
 	flag.Parse()
 
 	// Default backend ID
 	if backendID == "" {
 		backendID = fmt.Sprintf("mock-%s-%s", region, port)
 	}
+	if *advertiseAddr == "" {
+		*advertiseAddr = "localhost:" + port
+	}
 
 	// Get hostname
 	hostname, _ = os.Hostname()
 	startTime = time.Now()
 
 	// Routes
-	http.HandleFunc("/", handleRoot)
-	http.HandleFunc("/health", handleHealth)
-	http.HandleFunc("/api/info", handleInfo)
-	http.HandleFunc("/api/latency", handleLatency)
+	http.HandleFunc("/", instrument("/", handleRoot))
+	http.HandleFunc("/health", instrument("/health", handleHealth))
+	http.HandleFunc("/admin/health", instrument("/admin/health", handleAdminHealth))
+	http.HandleFunc("/api/info", instrument("/api/info", handleInfo))
+	http.HandleFunc("/api/latency", instrument("/api/latency", handleLatency))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *discoveryBackend != "none" {
+		reg, err := newRegistry(*discoveryBackend, *discoveryAddr, *discoveryService)
+		if err != nil {
+			log.Fatalf("discovery: %v", err)
+		}
+		go selfRegister(ctx, reg, *advertiseAddr, *weight)
+	}
+
+	if *eventsSink != "none" {
+		pub, err := newEventSink(*eventsSink, *eventsAddr, *eventsTarget)
+		if err != nil {
+			log.Fatalf("events: %v", err)
+		}
+		bus.AttachSink(ctx, pub)
+	}
+
+	bus.Publish(events.Event{Type: events.BackendUp, Timestamp: time.Now(), BackendID: backendID, Region: region})
+	go func() {
+		<-ctx.Done()
+		bus.Publish(events.Event{Type: events.BackendDown, Timestamp: time.Now(), BackendID: backendID, Region: region, Reason: "shutdown"})
+	}()
 
 	addr := ":" + port
 	log.Printf("Mock backend starting: id=%s region=%s port=%s", backendID, region, port)
-	log.Printf("Endpoints: / /health /api/info /api/latency")
+	log.Printf("Endpoints: / /health /admin/health /api/info /api/latency")
 
 	if err := http.ListenAndServe(addr, nil); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
+// newEventSink builds the events.Publisher named by kind, wiring it to
+// addr/target the same way newRegistry wires a discovery backend to
+// addr/service.
+func newEventSink(kind, addr, target string) (events.Publisher, error) {
+	switch kind {
+	case "nats":
+		return events.NewNATSPublisher(addr, target), nil
+	case "kafka":
+		return events.NewKafkaPublisher(addr, target), nil
+	case "redis":
+		return events.NewRedisStreamsPublisher(addr, target), nil
+	default:
+		return nil, fmt.Errorf("unknown events sink %q", kind)
+	}
+}
+
+// statusRecorder captures the status code a handler wrote, since
+// http.ResponseWriter doesn't expose it after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// instrument wraps a handler to emit RequestStarted/RequestCompleted
+// events carrying backend_id and region, without the handler itself
+// needing to know about the event bus.
+func instrument(path string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := fmt.Sprintf("%s-%d", backendID, atomic.AddUint64(&requestSeq, 1))
+		start := time.Now()
+
+		bus.Publish(events.Event{
+			Type: events.RequestStarted, Timestamp: start, BackendID: backendID, Region: region,
+			RequestID: requestID, Method: r.Method, Path: path,
+		})
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		bus.Publish(events.Event{
+			Type: events.RequestCompleted, Timestamp: time.Now(), BackendID: backendID, Region: region,
+			RequestID: requestID, Method: r.Method, Path: path,
+			StatusCode: rec.status, Duration: time.Since(start),
+		})
+	}
+}
+
+// newRegistry builds the discovery.Registry named by backend. consul and
+// etcd are address-based and configurable from flags; k8s reads in-cluster
+// config and ignores addr.
+func newRegistry(backend, addr, service string) (discovery.Registry, error) {
+	switch backend {
+	case "consul":
+		if addr == "" {
+			addr = "http://127.0.0.1:8500"
+		}
+		return discovery.NewConsulRegistry(addr, service), nil
+	case "etcd":
+		if addr == "" {
+			addr = "http://127.0.0.1:2379"
+		}
+		return discovery.NewEtcdRegistry(addr, "/"+service+"/"), nil
+	case "k8s":
+		return discovery.NewK8sRegistry(os.Getenv("POD_NAMESPACE"), service)
+	default:
+		return nil, fmt.Errorf("unknown discovery backend %q", backend)
+	}
+}
+
+// selfRegister heartbeats this backend's registry entry every third of the
+// TTL, reporting whatever handleHealth would currently report, and
+// deregisters immediately on shutdown so the proxy drains it instead of
+// waiting out the TTL.
+func selfRegister(ctx context.Context, reg discovery.Registry, advertiseAddr string, weight int) {
+	const ttl = 15 * time.Second
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	register := func() {
+		b := discovery.Backend{
+			ID:      backendID,
+			Region:  region,
+			Address: advertiseAddr,
+			Weight:  weight,
+			Healthy: atomic.LoadInt32(&healthy) == 1,
+		}
+		if err := reg.Register(ctx, b, ttl); err != nil {
+			log.Printf("discovery: register failed: %v", err)
+		}
+	}
+
+	register()
+	for {
+		select {
+		case <-ctx.Done():
+			deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := reg.Deregister(deregisterCtx, backendID); err != nil {
+				log.Printf("discovery: deregister failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			register()
+		}
+	}
+}
+
 func handleRoot(w http.ResponseWriter, r *http.Request) {
 	count := atomic.AddUint64(&requestCount, 1)
 
@@ -107,10 +267,42 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.Header().Set("X-Backend-ID", backendID)
 	w.Header().Set("X-Region", region)
+
+	if atomic.LoadInt32(&healthy) != 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "UNHEALTHY - %s (%s)", backendID, region)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "OK - %s (%s)", backendID, region)
 }
 
+// handleAdminHealth lets operators flip this backend's advertised health
+// without killing the process, for exercising the registry's
+// health-change/drain path end-to-end: PUT /admin/health?healthy=false
+func handleAdminHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Query().Get("healthy") {
+	case "false":
+		atomic.StoreInt32(&healthy, 0)
+		bus.Publish(events.Event{Type: events.BackendDown, Timestamp: time.Now(), BackendID: backendID, Region: region, Reason: "admin toggle"})
+	case "true":
+		atomic.StoreInt32(&healthy, 1)
+		bus.Publish(events.Event{Type: events.BackendUp, Timestamp: time.Now(), BackendID: backendID, Region: region})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "usage: PUT /admin/health?healthy=true|false")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "healthy=%v", atomic.LoadInt32(&healthy) == 1)
+}
+
 func handleInfo(w http.ResponseWriter, r *http.Request) {
 	count := atomic.AddUint64(&requestCount, 1)
 
@@ -0,0 +1,60 @@
+// Package acme auto-provisions and renews TLS certificates for proxied
+// hostnames via the ACME protocol (RFC 8555), supporting both HTTP-01 and
+// DNS-01 challenges. DNS-01 is driven by a pluggable DNSProvider so wildcard
+// certs and backends with no public HTTP listener are both covered.
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/tls"
+	"time"
+)
+
+// LetsEncryptDirectory and LetsEncryptStagingDirectory are the two ACME
+// directory URLs operators choose between - staging has much higher rate
+// limits and is what CI/dev environments should point at.
+const (
+	LetsEncryptDirectory        = "https://acme-v02.api.letsencrypt.org/directory"
+	LetsEncryptStagingDirectory = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+// ChallengeType selects how a host proves domain ownership to the CA.
+type ChallengeType string
+
+const (
+	ChallengeHTTP01 ChallengeType = "http-01"
+	ChallengeDNS01  ChallengeType = "dns-01"
+)
+
+// HostStatus is the per-host issuance state surfaced on the admin endpoint,
+// mirroring how the mock backend exposes its own state via /api/info.
+type HostStatus struct {
+	Host      string    `json:"host"`
+	State     string    `json:"state"` // pending | issued | failed | renewing
+	Challenge string    `json:"challenge,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	NotAfter  time.Time `json:"not_after,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Storage persists the ACME account key and issued certificates so a
+// restart doesn't re-register an account or re-issue certs that are still
+// valid. FileStorage is the default; a pluggable interface lets an operator
+// swap in S3/Consul-KV-backed storage the same way discovery.Registry lets
+// them swap backend-discovery implementations.
+type Storage interface {
+	LoadAccountKey() (*ecdsa.PrivateKey, error)
+	SaveAccountKey(key *ecdsa.PrivateKey) error
+
+	LoadCertificate(host string) (*tls.Certificate, error)
+	SaveCertificate(host string, cert *tls.Certificate) error
+}
+
+// DNSProvider presents and cleans up the TXT record a DNS-01 challenge
+// requires. fqdn is always "_acme-challenge.<host>." Implementations must
+// be safe to call from multiple goroutines with different hosts at once.
+type DNSProvider interface {
+	Present(fqdn, value string) error
+	CleanUp(fqdn, value string) error
+}
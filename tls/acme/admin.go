@@ -0,0 +1,15 @@
+package acme
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// AdminHandler serves the per-host issuance status as JSON, mirroring how
+// the mock backend exposes /api/info.
+func (m *Manager) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.Status())
+	})
+}
@@ -0,0 +1,60 @@
+package acme
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const http01Prefix = "/.well-known/acme-challenge/"
+
+// http01Responder answers HTTP-01 challenge requests. The CA's validation
+// server hits this path directly over plain HTTP, so it must be reachable
+// on the proxy's normal :80 listener, not just the admin port.
+type http01Responder struct {
+	mu     sync.Mutex
+	tokens map[string]string // token -> key authorization
+}
+
+func newHTTP01Responder() *http01Responder {
+	return &http01Responder{tokens: map[string]string{}}
+}
+
+func (h *http01Responder) set(token, keyAuth string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens[token] = keyAuth
+}
+
+func (h *http01Responder) remove(token string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.tokens, token)
+}
+
+// Handler serves /.well-known/acme-challenge/<token>; mount it on the same
+// HTTP server that terminates the proxied hostnames.
+func (h *http01Responder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.URL.Path, http01Prefix)
+
+		h.mu.Lock()
+		keyAuth, ok := h.tokens[token]
+		h.mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(keyAuth))
+	})
+}
+
+// dns01Value computes the TXT record content for _acme-challenge.<host>,
+// per RFC 8555 §8.4: base64url(SHA-256(keyAuthorization)).
+func dns01Value(keyAuth string) string {
+	sum := sha256.Sum256([]byte(keyAuth))
+	return b64(sum[:])
+}
@@ -0,0 +1,278 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// client is the thin ACME v2 (RFC 8555) transport: directory discovery,
+// nonce handling, and the signed-POST request/response cycle every other
+// operation (account, order, authorization, challenge, finalize) builds on.
+type client struct {
+	DirectoryURL string
+	AccountKey   *ecdsa.PrivateKey
+	KID          string // account URL, empty until CreateAccount succeeds
+
+	http      *http.Client
+	directory acmeDirectory
+	nonce     string
+}
+
+type acmeDirectory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+	NewOrder   string `json:"newOrder"`
+}
+
+func newClient(directoryURL string, key *ecdsa.PrivateKey) *client {
+	return &client{
+		DirectoryURL: directoryURL,
+		AccountKey:   key,
+		http:         &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *client) bootstrap() error {
+	resp, err := c.http.Get(c.DirectoryURL)
+	if err != nil {
+		return fmt.Errorf("acme: fetch directory: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&c.directory); err != nil {
+		return fmt.Errorf("acme: decode directory: %w", err)
+	}
+	return c.refreshNonce()
+}
+
+func (c *client) refreshNonce() error {
+	resp, err := c.http.Head(c.directory.NewNonce)
+	if err != nil {
+		return fmt.Errorf("acme: fetch nonce: %w", err)
+	}
+	defer resp.Body.Close()
+	c.nonce = resp.Header.Get("Replay-Nonce")
+	if c.nonce == "" {
+		return fmt.Errorf("acme: no Replay-Nonce header")
+	}
+	return nil
+}
+
+// post signs payload and POSTs it to url, retrying once if the server
+// rejects our nonce (badNonce is the one ACME error every client must
+// tolerate, since nonces are single-use and can race).
+func (c *client) post(url string, payload interface{}, out interface{}) (*http.Response, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		body, err := signJWS(c.AccountKey, c.KID, c.nonce, url, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/jose+json")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if n := resp.Header.Get("Replay-Nonce"); n != "" {
+			c.nonce = n
+		}
+
+		if resp.StatusCode >= 400 {
+			var probErr acmeProblem
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			json.Unmarshal(data, &probErr)
+			if probErr.Type == "urn:ietf:params:acme:error:badNonce" && attempt == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("acme: %s: %s (%s)", url, probErr.Detail, probErr.Type)
+		}
+
+		if out != nil {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return resp, fmt.Errorf("acme: decode response from %s: %w", url, err)
+			}
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("acme: %s: exhausted nonce retries", url)
+}
+
+type acmeProblem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+// createAccount registers AccountKey with the CA if it isn't already known,
+// populating c.KID for every subsequent request.
+func (c *client) createAccount() error {
+	var account struct {
+		Status string `json:"status"`
+	}
+	resp, err := c.post(c.directory.NewAccount, map[string]interface{}{
+		"termsOfServiceAgreed": true,
+	}, &account)
+	if err != nil {
+		return fmt.Errorf("acme: create account: %w", err)
+	}
+	c.KID = resp.Header.Get("Location")
+	if c.KID == "" {
+		return fmt.Errorf("acme: account response missing Location")
+	}
+	return nil
+}
+
+type acmeOrder struct {
+	Status         string   `json:"status"`
+	Authorizations []string `json:"authorizations"`
+	Finalize       string   `json:"finalize"`
+	Certificate    string   `json:"certificate,omitempty"`
+	URL            string   `json:"-"` // order URL, from newOrder's Location header
+}
+
+func (c *client) newOrder(hosts []string) (*acmeOrder, string, error) {
+	identifiers := make([]map[string]string, len(hosts))
+	for i, h := range hosts {
+		identifiers[i] = map[string]string{"type": "dns", "value": h}
+	}
+
+	var order acmeOrder
+	resp, err := c.post(c.directory.NewOrder, map[string]interface{}{"identifiers": identifiers}, &order)
+	if err != nil {
+		return nil, "", err
+	}
+	order.URL = resp.Header.Get("Location")
+	return &order, order.URL, nil
+}
+
+type acmeAuthorization struct {
+	Identifier struct {
+		Value string `json:"value"`
+	} `json:"identifier"`
+	Status     string `json:"status"`
+	Challenges []struct {
+		Type  string `json:"type"`
+		URL   string `json:"url"`
+		Token string `json:"token"`
+	} `json:"challenges"`
+}
+
+func (c *client) getAuthorization(url string) (*acmeAuthorization, error) {
+	var auth acmeAuthorization
+	if _, err := c.post(url, nil, &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+// respondChallenge tells the CA we believe challengeURL's preconditions are
+// met; the CA then validates asynchronously, so callers must poll
+// pollAuthorization afterward.
+func (c *client) respondChallenge(challengeURL string) error {
+	_, err := c.post(challengeURL, map[string]interface{}{}, nil)
+	return err
+}
+
+func (c *client) pollAuthorization(url string, timeout time.Duration) (*acmeAuthorization, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		auth, err := c.getAuthorization(url)
+		if err != nil {
+			return nil, err
+		}
+		switch auth.Status {
+		case "valid":
+			return auth, nil
+		case "invalid":
+			return auth, fmt.Errorf("acme: authorization for %s failed", auth.Identifier.Value)
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return nil, fmt.Errorf("acme: authorization for %s timed out", url)
+}
+
+// finalize submits the CSR and polls until the order is valid, then
+// downloads the issued certificate chain.
+func (c *client) finalize(order *acmeOrder, csrDER []byte, timeout time.Duration) ([][]byte, error) {
+	if _, err := c.post(order.Finalize, map[string]interface{}{"csr": b64(csrDER)}, nil); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var o acmeOrder
+		if _, err := c.post(order.URL, nil, &o); err != nil {
+			return nil, err
+		}
+		if o.Status == "valid" && o.Certificate != "" {
+			return c.downloadCertificate(o.Certificate)
+		}
+		if o.Status == "invalid" {
+			return nil, fmt.Errorf("acme: order went invalid during finalization")
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return nil, fmt.Errorf("acme: order finalization timed out")
+}
+
+func (c *client) downloadCertificate(url string) ([][]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := signJWS(c.AccountKey, c.KID, c.nonce, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/jose+json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if n := resp.Header.Get("Replay-Nonce"); n != "" {
+		c.nonce = n
+	}
+
+	pemChain, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return splitPEMCertificates(pemChain)
+}
+
+func splitPEMCertificates(data []byte) ([][]byte, error) {
+	var certs [][]byte
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+				return nil, err
+			}
+			certs = append(certs, block.Bytes)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("acme: no certificates in response")
+	}
+	return certs, nil
+}
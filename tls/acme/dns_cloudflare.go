@@ -0,0 +1,92 @@
+package acme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CloudflareProvider implements DNSProvider against Cloudflare's v4 API
+// using a scoped API token (Zone.DNS:Edit), via net/http directly rather
+// than vendoring a Cloudflare SDK.
+type CloudflareProvider struct {
+	APIToken string
+	ZoneID   string
+	client   *http.Client
+}
+
+func NewCloudflareProvider(apiToken, zoneID string) *CloudflareProvider {
+	return &CloudflareProvider{APIToken: apiToken, ZoneID: zoneID, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (p *CloudflareProvider) do(method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, "https://api.cloudflare.com/client/v4"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.APIToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool              `json:"success"`
+		Errors  []json.RawMessage `json:"errors"`
+		Result  json.RawMessage   `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("cloudflare: decode response: %w", err)
+	}
+	if !envelope.Success {
+		return fmt.Errorf("cloudflare: %s %s failed: %v", method, path, envelope.Errors)
+	}
+	if out != nil {
+		return json.Unmarshal(envelope.Result, out)
+	}
+	return nil
+}
+
+func (p *CloudflareProvider) Present(fqdn, value string) error {
+	name := strings.TrimSuffix(fqdn, ".")
+	return p.do(http.MethodPost, "/zones/"+p.ZoneID+"/dns_records", map[string]interface{}{
+		"type":    "TXT",
+		"name":    name,
+		"content": value,
+		"ttl":     120,
+	}, nil)
+}
+
+func (p *CloudflareProvider) CleanUp(fqdn, value string) error {
+	name := strings.TrimSuffix(fqdn, ".")
+
+	var records []struct {
+		ID string `json:"id"`
+	}
+	if err := p.do(http.MethodGet, "/zones/"+p.ZoneID+"/dns_records?type=TXT&name="+name, nil, &records); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := p.do(http.MethodDelete, "/zones/"+p.ZoneID+"/dns_records/"+rec.ID, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package acme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GandiProvider implements DNSProvider against Gandi's LiveDNS API using an
+// API key sent with the "Apikey" authorization scheme, via net/http directly.
+type GandiProvider struct {
+	APIKey string
+	Domain string // e.g. "example.com"
+	client *http.Client
+}
+
+func NewGandiProvider(apiKey, domain string) *GandiProvider {
+	return &GandiProvider{APIKey: apiKey, Domain: domain, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// recordName extracts the record name relative to p.Domain: "_acme-challenge"
+// for fqdn "_acme-challenge.example.com.".
+func (p *GandiProvider) recordName(fqdn string) string {
+	name := strings.TrimSuffix(fqdn, ".")
+	return strings.TrimSuffix(strings.TrimSuffix(name, p.Domain), ".")
+}
+
+func (p *GandiProvider) do(method, path string, body interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, "https://api.gandi.net/v5/livedns/domains/"+p.Domain+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Apikey "+p.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("gandi: %s %s: %s", method, path, resp.Status)
+	}
+	return nil
+}
+
+func (p *GandiProvider) Present(fqdn, value string) error {
+	name := p.recordName(fqdn)
+	return p.do(http.MethodPut, "/records/"+name+"/TXT", map[string]interface{}{
+		"rrset_ttl":    300,
+		"rrset_values": []string{`"` + value + `"`},
+	})
+}
+
+func (p *GandiProvider) CleanUp(fqdn, value string) error {
+	name := p.recordName(fqdn)
+	return p.do(http.MethodDelete, "/records/"+name+"/TXT", nil)
+}
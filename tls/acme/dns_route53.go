@@ -0,0 +1,154 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Route53Provider implements DNSProvider against Route53's REST API, SigV4
+// signed by hand the same way s3.go signs S3 requests - no AWS SDK is
+// vendored in this repo.
+type Route53Provider struct {
+	AccessKey string
+	SecretKey string
+	Region    string // SigV4 credential scope region, e.g. "us-east-1"
+	ZoneID    string
+	client    *http.Client
+}
+
+func NewRoute53Provider(accessKey, secretKey, region, zoneID string) *Route53Provider {
+	return &Route53Provider{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    region,
+		ZoneID:    zoneID,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type route53Change struct {
+	Action string `xml:"Action"`
+	RRS    struct {
+		Name string `xml:"Name"`
+		Type string `xml:"Type"`
+		TTL  int    `xml:"TTL"`
+		RRS  []struct {
+			Value string `xml:"Value"`
+		} `xml:"ResourceRecords>ResourceRecord"`
+	} `xml:"ResourceRecordSet"`
+}
+
+func (p *Route53Provider) changeRRSet(action, fqdn, value string) error {
+	type changeBatch struct {
+		XMLName xml.Name         `xml:"ChangeResourceRecordSetsRequest"`
+		Xmlns   string           `xml:"xmlns,attr"`
+		Changes []route53Change `xml:"ChangeBatch>Changes>Change"`
+	}
+
+	body := changeBatch{Xmlns: "https://route53.amazonaws.com/doc/2013-04-01/"}
+	c := route53Change{Action: action}
+	c.RRS.Name = fqdn
+	c.RRS.Type = "TXT"
+	c.RRS.TTL = 120
+	c.RRS.RRS = []struct {
+		Value string `xml:"Value"`
+	}{{Value: `"` + value + `"`}}
+	body.Changes = []route53Change{c}
+
+	payload, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://route53.amazonaws.com/2013-04-01/hostedzone/%s/rrset", p.ZoneID)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	if err := p.signSigV4(req, payload); err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("route53: %s %s: %s", action, fqdn, resp.Status)
+	}
+	return nil
+}
+
+func (p *Route53Provider) Present(fqdn, value string) error {
+	return p.changeRRSet("UPSERT", fqdn, value)
+}
+
+func (p *Route53Provider) CleanUp(fqdn, value string) error {
+	return p.changeRRSet("DELETE", fqdn, value)
+}
+
+// signSigV4 signs req for the "route53" service, reusing the same
+// canonical-request/string-to-sign/derived-key chain s3.go uses for S3.
+func (p *Route53Provider) signSigV4(req *http.Request, body []byte) error {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("Host", "route53.amazonaws.com")
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		"route53.amazonaws.com", payloadHash, amzDate)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders,
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/route53/aws4_request", dateStamp, p.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	kDate := hmacSHA256([]byte("AWS4"+p.SecretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, p.Region)
+	kService := hmacSHA256(kRegion, "route53")
+	signingKey := hmacSHA256(kService, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.AccessKey, credentialScope, strings.Join(signedHeaders, ";"), signature))
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,102 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// jwk is the JSON Web Key representation of an ECDSA P-256 public key, used
+// both in the JWS header (for the very first request, before an account
+// exists) and to compute the account's key thumbprint for challenges.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func publicJWK(key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   b64(leftPad(key.PublicKey.X.Bytes(), 32)),
+		Y:   b64(leftPad(key.PublicKey.Y.Bytes(), 32)),
+	}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint used to build the
+// key-authorization string every challenge response includes.
+func jwkThumbprint(key *ecdsa.PrivateKey) (string, error) {
+	pub := publicJWK(key)
+	// RFC 7638 requires the exact field order/no-whitespace form below.
+	canonical := fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s","y":"%s"}`, pub.Crv, pub.Kty, pub.X, pub.Y)
+	sum := sha256.Sum256([]byte(canonical))
+	return b64(sum[:]), nil
+}
+
+// signJWS produces a flattened JSON Web Signature over payload using ES256,
+// per RFC 8555 §6.2. Exactly one of kid or including the public JWK is set
+// in the protected header - kid once an account exists, the JWK itself only
+// for the account-creation request.
+func signJWS(key *ecdsa.PrivateKey, kid, nonce, url string, payload interface{}) ([]byte, error) {
+	var payloadJSON []byte
+	if payload == nil {
+		payloadJSON = []byte{} // POST-as-GET requests use an empty payload
+	} else {
+		var err error
+		payloadJSON, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	protected := map[string]interface{}{
+		"alg":   "ES256",
+		"nonce": nonce,
+		"url":   url,
+	}
+	if kid != "" {
+		protected["kid"] = kid
+	} else {
+		protected["jwk"] = publicJWK(key)
+	}
+
+	protectedJSON, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	protected64 := b64(protectedJSON)
+	payload64 := b64(payloadJSON)
+	signingInput := protected64 + "." + payload64
+
+	sum := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := append(leftPad(r.Bytes(), 32), leftPad(s.Bytes(), 32)...)
+
+	return json.Marshal(map[string]string{
+		"protected": protected64,
+		"payload":   payload64,
+		"signature": b64(sig),
+	})
+}
+
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
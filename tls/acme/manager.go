@@ -0,0 +1,291 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Config describes how a Manager should provision certificates for a fixed
+// set of hosts.
+type Config struct {
+	DirectoryURL string // LetsEncryptDirectory or LetsEncryptStagingDirectory
+	Hosts        []string
+	Challenge    ChallengeType
+	DNSProvider  DNSProvider // required when Challenge is ChallengeDNS01
+	Storage      Storage
+	Email        string
+
+	// RenewBefore is how far ahead of expiry the renewal loop re-issues;
+	// defaults to 30 days, matching Let's Encrypt's own recommendation.
+	RenewBefore time.Duration
+}
+
+// Manager issues and renews certificates for Config.Hosts and answers
+// tls.Config's GetCertificate callback, the same role autocert.Manager
+// plays, but with a pluggable DNSProvider for DNS-01.
+type Manager struct {
+	cfg      Config
+	client   *client
+	http01   *http01Responder
+	statusMu sync.RWMutex
+	status   map[string]*HostStatus
+
+	certMu sync.RWMutex
+	certs  map[string]*tls.Certificate
+}
+
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.RenewBefore == 0 {
+		cfg.RenewBefore = 30 * 24 * time.Hour
+	}
+	if cfg.Storage == nil {
+		return nil, fmt.Errorf("acme: Config.Storage is required")
+	}
+	if cfg.Challenge == ChallengeDNS01 && cfg.DNSProvider == nil {
+		return nil, fmt.Errorf("acme: Config.DNSProvider is required for dns-01")
+	}
+
+	m := &Manager{
+		cfg:    cfg,
+		http01: newHTTP01Responder(),
+		status: map[string]*HostStatus{},
+		certs:  map[string]*tls.Certificate{},
+	}
+	for _, h := range cfg.Hosts {
+		m.status[h] = &HostStatus{Host: h, State: "pending", UpdatedAt: time.Now()}
+	}
+
+	key, err := cfg.Storage.LoadAccountKey()
+	if err != nil {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("acme: generate account key: %w", err)
+		}
+		if err := cfg.Storage.SaveAccountKey(key); err != nil {
+			return nil, fmt.Errorf("acme: save account key: %w", err)
+		}
+	}
+	m.client = newClient(cfg.DirectoryURL, key)
+
+	for _, h := range cfg.Hosts {
+		if cert, err := cfg.Storage.LoadCertificate(h); err == nil {
+			m.certMu.Lock()
+			m.certs[h] = cert
+			m.certMu.Unlock()
+			m.setStatus(h, "issued", "", cert.Leaf, nil)
+		}
+	}
+
+	return m, nil
+}
+
+// HTTPHandler returns the handler that must be mounted at
+// /.well-known/acme-challenge/ on the plaintext :80 listener for HTTP-01 to
+// work.
+func (m *Manager) HTTPHandler() http.Handler {
+	return m.http01.Handler()
+}
+
+// GetCertificate implements tls.Config.GetCertificate, serving the most
+// recently issued certificate for hello.ServerName.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.certMu.RLock()
+	cert, ok := m.certs[hello.ServerName]
+	m.certMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("acme: no certificate for %s", hello.ServerName)
+	}
+	return cert, nil
+}
+
+// Status returns a snapshot of every configured host's issuance state, for
+// the admin endpoint.
+func (m *Manager) Status() []HostStatus {
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+
+	out := make([]HostStatus, 0, len(m.status))
+	for _, s := range m.status {
+		out = append(out, *s)
+	}
+	return out
+}
+
+func (m *Manager) setStatus(host, state, challenge string, leaf *x509.Certificate, err error) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	s := m.status[host]
+	if s == nil {
+		s = &HostStatus{Host: host}
+		m.status[host] = s
+	}
+	s.State = state
+	s.Challenge = challenge
+	s.UpdatedAt = time.Now()
+	if leaf != nil {
+		s.NotBefore = leaf.NotBefore
+		s.NotAfter = leaf.NotAfter
+	}
+	if err != nil {
+		s.Error = err.Error()
+	} else {
+		s.Error = ""
+	}
+}
+
+// Run issues certificates for any host missing one, then loops forever
+// renewing certs within RenewBefore of expiry, until ctx is canceled. It is
+// meant to run in its own goroutine for the lifetime of the proxy process.
+func (m *Manager) Run(ctx context.Context) {
+	// First pass: issue anything we don't already have loaded from storage.
+	for _, h := range m.cfg.Hosts {
+		m.certMu.RLock()
+		_, have := m.certs[h]
+		m.certMu.RUnlock()
+		if !have {
+			m.issue(h)
+		}
+	}
+
+	ticker := time.NewTicker(12 * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, h := range m.cfg.Hosts {
+				m.certMu.RLock()
+				cert, have := m.certs[h]
+				m.certMu.RUnlock()
+				if !have || cert.Leaf == nil || time.Until(cert.Leaf.NotAfter) < m.cfg.RenewBefore {
+					m.setStatus(h, "renewing", "", nil, nil)
+					m.issue(h)
+				}
+			}
+		}
+	}
+}
+
+func (m *Manager) issue(host string) {
+	if err := m.client.bootstrap(); err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+	if m.client.KID == "" {
+		if err := m.client.createAccount(); err != nil {
+			m.setStatus(host, "failed", "", nil, err)
+			return
+		}
+	}
+
+	order, _, err := m.client.newOrder([]string{host})
+	if err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+
+	thumbprint, err := jwkThumbprint(m.client.AccountKey)
+	if err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+
+	for _, authURL := range order.Authorizations {
+		if err := m.completeAuthorization(host, authURL, thumbprint); err != nil {
+			m.setStatus(host, "failed", string(m.cfg.Challenge), nil, err)
+			return
+		}
+	}
+
+	key, err := newHostKey()
+	if err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: host},
+		DNSNames: []string{host},
+	}, key)
+	if err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+
+	chain, err := m.client.finalize(order, csrDER, 2*time.Minute)
+	if err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+	cert := &tls.Certificate{Certificate: chain, PrivateKey: key, Leaf: leaf}
+
+	if err := m.cfg.Storage.SaveCertificate(host, cert); err != nil {
+		m.setStatus(host, "failed", "", nil, err)
+		return
+	}
+
+	m.certMu.Lock()
+	m.certs[host] = cert
+	m.certMu.Unlock()
+	m.setStatus(host, "issued", "", leaf, nil)
+}
+
+func (m *Manager) completeAuthorization(host, authURL, thumbprint string) error {
+	auth, err := m.client.getAuthorization(authURL)
+	if err != nil {
+		return err
+	}
+	if auth.Status == "valid" {
+		return nil
+	}
+
+	for _, chal := range auth.Challenges {
+		if chal.Type != string(m.cfg.Challenge) {
+			continue
+		}
+
+		keyAuth := chal.Token + "." + thumbprint
+
+		switch m.cfg.Challenge {
+		case ChallengeHTTP01:
+			m.http01.set(chal.Token, keyAuth)
+			defer m.http01.remove(chal.Token)
+		case ChallengeDNS01:
+			fqdn := "_acme-challenge." + host + "."
+			value := dns01Value(keyAuth)
+			if err := m.cfg.DNSProvider.Present(fqdn, value); err != nil {
+				return fmt.Errorf("acme: dns-01 present: %w", err)
+			}
+			defer m.cfg.DNSProvider.CleanUp(fqdn, value)
+			// DNS propagation is the long pole for dns-01; give
+			// authoritative servers a moment before asking the CA to check.
+			time.Sleep(10 * time.Second)
+		}
+
+		if err := m.client.respondChallenge(chal.URL); err != nil {
+			return err
+		}
+		if _, err := m.client.pollAuthorization(authURL, 2*time.Minute); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	return fmt.Errorf("acme: no %s challenge offered for %s", m.cfg.Challenge, host)
+}
@@ -0,0 +1,96 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileStorage keeps the account key and one cert+key pair per host under
+// Dir, PEM-encoded, matching how an operator would lay out certs for any
+// other TLS-terminating proxy.
+type FileStorage struct {
+	Dir string
+}
+
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{Dir: dir}
+}
+
+func (s *FileStorage) accountKeyPath() string      { return filepath.Join(s.Dir, "account.key") }
+func (s *FileStorage) certPath(host string) string { return filepath.Join(s.Dir, host+".crt") }
+func (s *FileStorage) keyPath(host string) string  { return filepath.Join(s.Dir, host+".key") }
+
+func (s *FileStorage) LoadAccountKey() (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(s.accountKeyPath())
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("acme: invalid account key PEM")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+func (s *FileStorage) SaveAccountKey(key *ecdsa.PrivateKey) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	block := &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	return os.WriteFile(s.accountKeyPath(), pem.EncodeToMemory(block), 0600)
+}
+
+func (s *FileStorage) LoadCertificate(host string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(s.certPath(host), s.keyPath(host))
+	if err != nil {
+		return nil, err
+	}
+	if len(cert.Certificate) > 0 {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			cert.Leaf = leaf
+		}
+	}
+	return &cert, nil
+}
+
+func (s *FileStorage) SaveCertificate(host string, cert *tls.Certificate) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+
+	var certPEM []byte
+	for _, der := range cert.Certificate {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	if err := os.WriteFile(s.certPath(host), certPEM, 0644); err != nil {
+		return err
+	}
+
+	key, ok := cert.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("acme: expected ecdsa private key for %s", host)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return os.WriteFile(s.keyPath(host), keyPEM, 0600)
+}
+
+// newHostKey generates the P-256 key pair a freshly-issued certificate uses;
+// ACME account keys and leaf-certificate keys are kept separate on purpose.
+func newHostKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}